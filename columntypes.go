@@ -0,0 +1,120 @@
+package tblfmt
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+)
+
+// columnType describes a column of a result set that is not backed by a
+// database.
+type columnType struct {
+	// name is the column name.
+	name string
+	// typ is the scan type.
+	typ reflect.Type
+	// dbType is the database type name.
+	dbType string
+	// nullable indicates the column is nullable.
+	nullable bool
+}
+
+// newColumnTypes builds standard *sql.ColumnType values for the columns.
+//
+// As database/sql does not provide a way to create column types directly, the
+// column types are created by querying a database handle using a driver that
+// returns an empty result with the columns.
+func newColumnTypes(cols []columnType) ([]*sql.ColumnType, error) {
+	db := sql.OpenDB(columnTypesConnector(cols))
+	defer db.Close()
+	rows, err := db.Query("")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.ColumnTypes()
+}
+
+// columnTypesConnector is a driver connector for column types.
+type columnTypesConnector []columnType
+
+// Connect satisfies the driver.Connector interface.
+func (c columnTypesConnector) Connect(context.Context) (driver.Conn, error) {
+	return columnTypesConn(c), nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c columnTypesConnector) Driver() driver.Driver {
+	return columnTypesDriver{}
+}
+
+// columnTypesDriver is the driver for column types.
+type columnTypesDriver struct{}
+
+// Open satisfies the driver.Driver interface.
+func (columnTypesDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+// columnTypesConn is a driver connection for column types.
+type columnTypesConn []columnType
+
+// Prepare satisfies the driver.Conn interface.
+func (columnTypesConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+// Close satisfies the driver.Conn interface.
+func (columnTypesConn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (columnTypesConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c columnTypesConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return columnTypesRows(c), nil
+}
+
+// columnTypesRows are empty driver rows for column types.
+type columnTypesRows []columnType
+
+// Columns satisfies the driver.Rows interface.
+func (r columnTypesRows) Columns() []string {
+	cols := make([]string, len(r))
+	for i := range r {
+		cols[i] = r[i].name
+	}
+	return cols
+}
+
+// Close satisfies the driver.Rows interface.
+func (columnTypesRows) Close() error {
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (columnTypesRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r columnTypesRows) ColumnTypeScanType(i int) reflect.Type {
+	return r[i].typ
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r columnTypesRows) ColumnTypeDatabaseTypeName(i int) string {
+	return r[i].dbType
+}
+
+// ColumnTypeNullable satisfies the driver.RowsColumnTypeNullable interface.
+func (r columnTypesRows) ColumnTypeNullable(i int) (bool, bool) {
+	return r[i].nullable, true
+}
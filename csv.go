@@ -0,0 +1,465 @@
+package tblfmt
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CSVResultSet is a result set for CSV (or TSV) data, read using the standard
+// [encoding/csv.Reader]. Each reader is a separate result set.
+type CSVResultSet struct {
+	// readers are the readers.
+	readers []io.Reader
+	// sep is the field separator.
+	sep rune
+	// quote is the field quote.
+	quote rune
+	// header toggles reading the column names from the first row.
+	header bool
+	// names are the supplied column names.
+	names []string
+	// infer is the number of rows used to infer column types.
+	infer int
+	// r is the current csv reader.
+	r *csv.Reader
+	// cols are the current columns.
+	cols []string
+	// kinds are the current column kinds.
+	kinds []csvKind
+	// rows are the buffered rows.
+	rows [][]string
+	// row is the current row.
+	row []string
+	// types are the column types.
+	types []*sql.ColumnType
+	// err is the last encountered error.
+	err error
+}
+
+// FromCSV creates a result set for CSV data read from the readers, with each
+// reader being a separate result set.
+//
+// By default, fields are separated by ',' and quoted with '"', and the first
+// row of each reader contains the column names. Use the [WithSeparator],
+// [WithQuote], [WithHeaderRow], [WithColumnNames], and [WithInferTypes]
+// options to change the defaults. For TSV data, use WithSeparator('\t').
+func FromCSV(readers []io.Reader, opts ...Option) (*CSVResultSet, error) {
+	r := &CSVResultSet{
+		readers: readers,
+		sep:     ',',
+		quote:   '"',
+		header:  true,
+	}
+	for _, o := range opts {
+		if err := o.apply(r); err != nil {
+			return nil, err
+		}
+	}
+	if len(readers) == 0 {
+		return nil, ErrResultSetIsNil
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the first reader, reading the column names and inferring the
+// column types.
+func (r *CSVResultSet) open() error {
+	// build reader
+	var rd io.Reader = r.readers[0]
+	if q := r.quoteRune(); q != '"' {
+		rd = &swapReader{r: bufio.NewReader(rd), a: '"', b: q}
+	}
+	r.r = csv.NewReader(rd)
+	r.r.Comma, r.r.LazyQuotes, r.r.FieldsPerRecord = r.sep, r.quote == 0, -1
+	r.readers, r.cols, r.kinds, r.rows, r.types = r.readers[1:], nil, nil, nil, nil
+	// read header
+	if r.header {
+		row, err := r.read()
+		switch {
+		case err == io.EOF:
+			return ErrResultSetHasNoColumns
+		case err != nil:
+			return err
+		}
+		r.cols = row
+	}
+	// buffer rows
+	n := 1
+	if r.infer < 0 || n < r.infer {
+		n = r.infer
+	}
+	for ; n != 0; n-- {
+		row, err := r.read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		r.rows = append(r.rows, row)
+	}
+	switch {
+	case r.names != nil:
+		r.cols = r.names
+	case !r.header:
+		if len(r.rows) == 0 {
+			return ErrResultSetHasNoColumns
+		}
+		r.cols = make([]string, len(r.rows[0]))
+		for i := range r.cols {
+			r.cols[i] = "column" + strconv.Itoa(i+1)
+		}
+	}
+	// infer types
+	r.kinds = make([]csvKind, len(r.cols))
+	if r.infer != 0 {
+		for i := range r.kinds {
+			r.kinds[i] = inferCSVKind(r.rows, i)
+		}
+	}
+	return nil
+}
+
+// read reads a row.
+func (r *CSVResultSet) read() ([]string, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	if q := r.quoteRune(); q != '"' {
+		for i := range row {
+			row[i] = swapRunes(row[i], '"', q)
+		}
+	}
+	return row, nil
+}
+
+// quoteRune returns the quote rune for the csv reader.
+//
+// As the csv reader only supports '"' quotes, other quotes are swapped with
+// '"' when reading. When quoting is disabled, '"' is swapped with the U+FFFF
+// noncharacter instead.
+func (r *CSVResultSet) quoteRune() rune {
+	if r.quote == 0 {
+		return '\uffff'
+	}
+	return r.quote
+}
+
+// Next satisfies the ResultSet interface.
+func (r *CSVResultSet) Next() bool {
+	switch {
+	case r.err != nil || r.r == nil:
+		return false
+	case len(r.rows) != 0:
+		r.row, r.rows = r.rows[0], r.rows[1:]
+		return true
+	}
+	var err error
+	if r.row, err = r.read(); err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.r = nil
+		return false
+	}
+	return true
+}
+
+// Scan satisfies the ResultSet interface.
+//
+// When column types have been inferred, empty fields are scanned as nil, and
+// fields are converted to an int64, float64, bool, or time.Time. Fields that
+// cannot be converted are scanned as a string.
+func (r *CSVResultSet) Scan(dest ...any) error {
+	if r.row == nil {
+		return sql.ErrNoRows
+	}
+	if len(dest) != len(r.cols) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.cols), len(dest))
+	}
+	for i := range dest {
+		var val any
+		if i < len(r.row) {
+			val = r.kinds[i].parse(r.row[i])
+		}
+		if err := assign(dest[i], val, -1); err != nil {
+			return fmt.Errorf("column %d (%s): %w", i, r.cols[i], err)
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (r *CSVResultSet) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+// ColumnTypes returns the column types. When all rows were used to infer the
+// column types (see [WithInferTypes]), the scan types are sql.NullInt64,
+// sql.NullFloat64, sql.NullBool, or sql.NullTime for columns with an inferred
+// type. Otherwise, as later fields may not be converted, the scan type of
+// columns with an inferred type is any. The scan type of other columns is
+// string.
+func (r *CSVResultSet) ColumnTypes() ([]*sql.ColumnType, error) {
+	if r.types == nil {
+		cols := make([]columnType, len(r.cols))
+		for i, name := range r.cols {
+			cols[i] = r.kinds[i].columnType(name, r.infer < 0)
+		}
+		var err error
+		if r.types, err = newColumnTypes(cols); err != nil {
+			return nil, err
+		}
+	}
+	return r.types, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (r *CSVResultSet) Close() error {
+	r.r, r.readers, r.rows = nil, nil, nil
+	return nil
+}
+
+// Err satisfies the ResultSet interface.
+func (r *CSVResultSet) Err() error {
+	return r.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (r *CSVResultSet) NextResultSet() bool {
+	if r.err != nil || len(r.readers) == 0 {
+		return false
+	}
+	if r.err = r.open(); r.err != nil {
+		return false
+	}
+	return true
+}
+
+// csvKind is an inferred csv column kind.
+type csvKind int
+
+// csvKind values.
+const (
+	csvString csvKind = iota
+	csvInt
+	csvFloat
+	csvBool
+	csvDate
+	csvTimestamp
+	csvTimestampTz
+)
+
+// csvLayouts are the time layouts tried when inferring time columns, and the
+// kind of each layout.
+var csvLayouts = []struct {
+	layout string
+	kind   csvKind
+}{
+	{time.RFC3339Nano, csvTimestampTz},
+	{"2006-01-02 15:04:05.999999999Z07:00", csvTimestampTz},
+	{"2006-01-02 15:04:05.999999999Z07", csvTimestampTz},
+	{"2006-01-02T15:04:05.999999999", csvTimestamp},
+	{"2006-01-02 15:04:05.999999999", csvTimestamp},
+	{time.DateOnly, csvDate},
+}
+
+// inferCSVKind infers the kind of column i from the non-empty fields of the
+// rows.
+func inferCSVKind(rows [][]string, i int) csvKind {
+	var kind csvKind
+	for _, row := range rows {
+		if len(row) <= i || row[i] == "" {
+			continue
+		}
+		k := csvKindOf(row[i])
+		switch {
+		case kind == csvString:
+			kind = k
+		case kind == k:
+		case kind == csvInt && k == csvFloat, kind == csvFloat && k == csvInt:
+			kind = csvFloat
+		case kind >= csvDate && k >= csvDate:
+			kind = max(kind, k)
+		default:
+			return csvString
+		}
+		if kind == csvString {
+			return csvString
+		}
+	}
+	return kind
+}
+
+// csvKindOf returns the kind of a field.
+func csvKindOf(s string) csvKind {
+	if _, err := parseCSVInt(s); err == nil {
+		return csvInt
+	}
+	if _, err := parseCSVFloat(s); err == nil {
+		return csvFloat
+	}
+	if _, err := parseCSVBool(s); err == nil {
+		return csvBool
+	}
+	for _, l := range csvLayouts {
+		if _, err := time.Parse(l.layout, s); err == nil {
+			return l.kind
+		}
+	}
+	return csvString
+}
+
+// parseCSVInt parses an int field. Fields with a leading zero (such as zip
+// codes, or "007") are not parsed.
+func parseCSVInt(s string) (int64, error) {
+	if hasLeadingZero(s) {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// parseCSVFloat parses a float field. Fields with a leading zero, or
+// containing letters other than an exponent (such as "NaN", "Inf", or hex
+// floats) are not parsed.
+func parseCSVFloat(s string) (float64, error) {
+	if hasLeadingZero(s) || strings.IndexFunc(s, func(c rune) bool {
+		return c != 'e' && c != 'E' && unicode.IsLetter(c)
+	}) != -1 {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseCSVBool parses a bool field, either "true" or "false" in any case.
+func parseCSVBool(s string) (bool, error) {
+	switch {
+	case strings.EqualFold(s, "true"):
+		return true, nil
+	case strings.EqualFold(s, "false"):
+		return false, nil
+	}
+	return false, strconv.ErrSyntax
+}
+
+// hasLeadingZero returns true when s, after any sign, starts with a zero
+// followed by a digit.
+func hasLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 1 && s[0] == '0' && '0' <= s[1] && s[1] <= '9'
+}
+
+// parse parses the field as the kind, returning nil for empty fields of
+// inferred kinds, and the field as-is when it cannot be parsed.
+func (kind csvKind) parse(s string) any {
+	switch {
+	case kind == csvString:
+		return s
+	case s == "":
+		return nil
+	case kind == csvInt:
+		if i, err := parseCSVInt(s); err == nil {
+			return i
+		}
+	case kind == csvFloat:
+		if f, err := parseCSVFloat(s); err == nil {
+			return f
+		}
+	case kind == csvBool:
+		if b, err := parseCSVBool(s); err == nil {
+			return b
+		}
+	default:
+		for _, l := range csvLayouts {
+			if t, err := time.Parse(l.layout, s); err == nil {
+				return t
+			}
+		}
+	}
+	return s
+}
+
+// columnType returns the column type for the kind. When not exhaustive, the
+// scan type of inferred kinds is any, as fields are scanned as a string when
+// they cannot be converted.
+func (kind csvKind) columnType(name string, exhaustive bool) columnType {
+	typ, dbType := reflect.TypeFor[string](), "TEXT"
+	switch kind {
+	case csvInt:
+		typ, dbType = reflect.TypeFor[sql.NullInt64](), "BIGINT"
+	case csvFloat:
+		typ, dbType = reflect.TypeFor[sql.NullFloat64](), "DOUBLE PRECISION"
+	case csvBool:
+		typ, dbType = reflect.TypeFor[sql.NullBool](), "BOOLEAN"
+	case csvDate:
+		typ, dbType = reflect.TypeFor[sql.NullTime](), "DATE"
+	case csvTimestamp:
+		typ, dbType = reflect.TypeFor[sql.NullTime](), "TIMESTAMP"
+	case csvTimestampTz:
+		typ, dbType = reflect.TypeFor[sql.NullTime](), "TIMESTAMPTZ"
+	}
+	if kind != csvString && !exhaustive {
+		typ = reflect.TypeFor[any]()
+	}
+	return columnType{
+		name:     name,
+		typ:      typ,
+		dbType:   dbType,
+		nullable: kind != csvString,
+	}
+}
+
+// swapReader is a reader that swaps two runes. Invalid UTF-8 bytes are passed
+// through unchanged.
+type swapReader struct {
+	r    *bufio.Reader
+	a, b rune
+}
+
+// Read satisfies the io.Reader interface.
+func (r *swapReader) Read(p []byte) (int, error) {
+	var n int
+	for n+utf8.UTFMax <= len(p) {
+		c, size, err := r.r.ReadRune()
+		if err != nil {
+			if n != 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		switch {
+		case c == utf8.RuneError && size == 1:
+			// reread the invalid byte
+			_ = r.r.UnreadRune()
+			p[n], _ = r.r.ReadByte()
+			n++
+		case c == r.a:
+			n += utf8.EncodeRune(p[n:], r.b)
+		case c == r.b:
+			n += utf8.EncodeRune(p[n:], r.a)
+		default:
+			n += utf8.EncodeRune(p[n:], c)
+		}
+		if r.r.Buffered() == 0 {
+			break
+		}
+	}
+	return n, nil
+}
+
+// swapRunes swaps two runes in s, leaving invalid UTF-8 bytes unchanged.
+func swapRunes(s string, a, b rune) string {
+	return strings.NewReplacer(string(a), string(b), string(b), string(a)).Replace(s)
+}
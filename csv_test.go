@@ -0,0 +1,149 @@
+package tblfmt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromCSV(t *testing.T) {
+	t.Parallel()
+	readers := []io.Reader{
+		strings.NewReader("name,qty,price,ok,at\n" +
+			"'a, b',1,1.5,true,2024-01-02\n" +
+			"'c ''d''',,2,false,2024-01-03\n"),
+		strings.NewReader("x\ty\n1\t\"2\n"),
+	}
+	rs, err := FromCSV(readers, WithQuote('\''), WithInferTypes(-1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := ` name  | qty | price |  ok   |     at     
+-------+-----+-------+-------+------------
+ a, b  |   1 |   1.5 | true  | 2024-01-02 
+ c 'd' |     |     2 | false | 2024-01-03 
+(2 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, rs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+	rs, err = FromCSV(readers[1:], WithSeparator('\t'), WithQuote(0), WithHeaderRow(false))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp = `[{"column1":"x","column2":"y"},{"column1":"1","column2":"\"2"}]`
+	buf.Reset()
+	if err := EncodeJSON(buf, rs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestFromCSVAll(t *testing.T) {
+	t.Parallel()
+	rs, err := FromCSV([]io.Reader{
+		strings.NewReader("a,b\n1,x\n"),
+		strings.NewReader("c\n2.5\n"),
+	}, WithInferTypes(1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `a|b
+1|x
+
+c
+2.5
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeUnalignedAll(buf, rs, WithUseColumnTypes(true), WithSummary(Summary{})); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestFromCSVInferFloat(t *testing.T) {
+	t.Parallel()
+	rs, err := FromCSV([]io.Reader{
+		strings.NewReader("a,b,c\nNaN,1e3,1.5\nInf,2,infinity\n"),
+	}, WithInferTypes(-1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `[{"a":"NaN","b":1000,"c":"1.5"},{"a":"Inf","b":2,"c":"infinity"}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, rs, WithUseColumnTypes(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestFromCSVInvalidUTF8(t *testing.T) {
+	t.Parallel()
+	for _, quote := range []rune{'\'', 0} {
+		rs, err := FromCSV([]io.Reader{strings.NewReader("a\n'x\xff\"y'\n")}, WithQuote(quote))
+		if err != nil {
+			t.Fatalf("quote %q expected no error, got: %v", quote, err)
+		}
+		exp := "x\xff\"y"
+		if quote == 0 {
+			exp = "'" + exp + "'"
+		}
+		if !rs.Next() {
+			t.Fatalf("quote %q expected a row", quote)
+		}
+		var v string
+		if err := rs.Scan(&v); err != nil {
+			t.Fatalf("quote %q expected no error, got: %v", quote, err)
+		}
+		if v != exp {
+			t.Errorf("quote %q expected %q, got: %q", quote, exp, v)
+		}
+	}
+}
+
+func TestFromCSVInferLeadingZero(t *testing.T) {
+	t.Parallel()
+	rs, err := FromCSV([]io.Reader{
+		strings.NewReader("zip,n,f,ok\n01234,0,0.5,tRuE\n98765,-7,-0.25,FALSE\n"),
+	}, WithInferTypes(-1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `[{"zip":"01234","n":0,"f":0.5,"ok":true},{"zip":"98765","n":-7,"f":-0.25,"ok":false}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, rs, WithUseColumnTypes(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestFromCSVInferSample(t *testing.T) {
+	t.Parallel()
+	rs, err := FromCSV([]io.Reader{
+		strings.NewReader("n,ok\n1,true\nx,007\n"),
+	}, WithInferTypes(1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `[{"n":1,"ok":true},{"n":"x","ok":"007"}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, rs, WithUseColumnTypes(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
@@ -0,0 +1,573 @@
+package tblfmt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DiffKind is the kind of a diff row.
+type DiffKind rune
+
+// Diff kinds.
+const (
+	// DiffAdded is a row only in the second result set.
+	DiffAdded DiffKind = '+'
+	// DiffRemoved is a row only in the first result set.
+	DiffRemoved DiffKind = '-'
+	// DiffChanged is a row in both result sets with changed values.
+	DiffChanged DiffKind = '~'
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (kind DiffKind) String() string {
+	return string(kind)
+}
+
+// DiffRow is a diff row.
+type DiffRow struct {
+	// Kind is the kind of the row.
+	Kind DiffKind
+	// Old is the row in the first result set. Nil for added rows.
+	Old []any
+	// New is the row in the second result set. Nil for removed rows.
+	New []any
+	// Changed indicates the columns with changed values.
+	Changed []bool
+}
+
+// DiffValue is a changed diff value.
+//
+// The [EscapeFormatter] formats changed diff values as the old and new values
+// separated by an arrow, optionally highlighted (see [WithDiffHighlight]).
+type DiffValue struct {
+	Old any
+	New any
+}
+
+// String satisfies the [fmt.Stringer] interface.
+func (v DiffValue) String() string {
+	a, b := "NULL", "NULL"
+	if v.Old != nil {
+		a = toString(v.Old)
+	}
+	if v.New != nil {
+		b = toString(v.New)
+	}
+	return a + " → " + b
+}
+
+// DiffView is a diff view of two result sets, producing the rows added,
+// removed, or changed in the second result set, matched by key columns (see
+// [WithKeyColumns]). At least one key column is required.
+//
+// The view's first column is the diff marker (see [DiffKind]), followed by
+// the columns of the result sets. Added and removed rows contain their
+// values, and changed rows contain the new values, with changed values as a
+// [DiffValue].
+//
+// Unless both result sets are sorted by the key columns (see
+// [WithSortedInputs]), the rows of the first result set are read into memory.
+// As with the crosstab view, NextResultSet always returns false.
+type DiffView struct {
+	// a is the first result set.
+	a ResultSet
+	// b is the second result set.
+	b ResultSet
+	// formatter is the formatter.
+	formatter Formatter
+	// keys are the key columns.
+	keys []string
+	// sorted toggles streaming sorted result sets.
+	sorted bool
+	// labels are the labels of the result sets.
+	labels [2]string
+	// cols are the column names.
+	cols []string
+	// kindexes are the key column indexes.
+	kindexes []int
+	// arows are the rows of the first result set.
+	arows [][]any
+	// index is the index of the first result set's rows by key.
+	index map[string][]int
+	// matched are the first result set's matched rows.
+	matched []bool
+	// pos is the index of the next unmatched row.
+	pos int
+	// arow and brow are the next rows of sorted result sets.
+	arow, brow []any
+	// row is the current row.
+	row DiffRow
+	// err is the last encountered error.
+	err error
+}
+
+// NewDiffView creates a new diff view of the result sets, returning
+// [ErrDiffNoKeyColumns] when no key columns are set.
+func NewDiffView(a, b ResultSet, opts ...Option) (*DiffView, error) {
+	view := &DiffView{
+		a:         a,
+		b:         b,
+		formatter: NewEscapeFormatter(WithIsRaw(true, 0, 0)),
+		labels:    [2]string{"a", "b"},
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// build builds the diff view.
+func (view *DiffView) build() error {
+	acols, err := view.a.Columns()
+	if err != nil {
+		return view.fail(err)
+	}
+	bcols, err := view.b.Columns()
+	if err != nil {
+		return view.fail(err)
+	}
+	if !slices.Equal(acols, bcols) {
+		return view.fail(ErrDiffColumnsDoNotMatch)
+	}
+	view.cols = acols
+	if len(view.keys) == 0 {
+		return view.fail(ErrDiffNoKeyColumns)
+	}
+	for _, s := range view.keys {
+		i := indexOf(view.cols, s)
+		if i == -1 {
+			return view.fail(ErrDiffKeyColumnNotInResult)
+		}
+		view.kindexes = append(view.kindexes, i)
+	}
+	if view.sorted {
+		return nil
+	}
+	view.index = make(map[string][]int)
+	for {
+		row, err := view.read(view.a)
+		switch {
+		case err != nil:
+			return view.fail(err)
+		case row == nil:
+			view.matched = make([]bool, len(view.arows))
+			return nil
+		}
+		k := view.key(row)
+		view.index[k] = append(view.index[k], len(view.arows))
+		view.arows = append(view.arows, row)
+	}
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *DiffView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// read reads the next row of the result set, returning nil when there are no
+// more rows.
+func (view *DiffView) read(resultSet ResultSet) ([]any, error) {
+	if !resultSet.Next() {
+		return nil, resultSet.Err()
+	}
+	r := make([]any, len(view.cols))
+	for i := range r {
+		r[i] = new(any)
+	}
+	if err := resultSet.Scan(r...); err != nil {
+		return nil, err
+	}
+	for i := range r {
+		r[i] = driverValue(r[i])
+	}
+	return r, nil
+}
+
+// key returns the key of a row. Rows have the same key only when their key
+// values are of the same type and equal, the same as when comparing keys.
+func (view *DiffView) key(row []any) string {
+	var sb strings.Builder
+	for _, i := range view.kindexes {
+		sb.WriteString(keyString(row[i]))
+		sb.WriteByte(0x1f)
+	}
+	return sb.String()
+}
+
+// compareKeys compares the keys of rows a and b. Key values are only equal
+// when of the same type and equal (see [equalValues]), and are otherwise
+// ordered by value, and then by type.
+func (view *DiffView) compareKeys(a, b []any) int {
+	for _, i := range view.kindexes {
+		if equalValues(a[i], b[i]) {
+			continue
+		}
+		if c := compareValues(a[i], b[i], nil); c != 0 {
+			return c
+		}
+		if c := strings.Compare(keyString(a[i]), keyString(b[i])); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// keyString returns the string form of a key value, including its type.
+func keyString(v any) string {
+	switch z := unwrapValue(v).(type) {
+	case nil:
+		return "\x00"
+	case time.Time:
+		return "time.Time:" + z.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%T:%s", z, toString(z))
+	}
+}
+
+// change sets the current row to a changed row when the values of a and b
+// differ.
+func (view *DiffView) change(a, b []any) bool {
+	changed, ok := make([]bool, len(a)), false
+	for i := range a {
+		if !equalValues(a[i], b[i]) {
+			changed[i], ok = true, true
+		}
+	}
+	if ok {
+		view.row = DiffRow{Kind: DiffChanged, Old: a, New: b, Changed: changed}
+	}
+	return ok
+}
+
+// equalValues returns true when a and b are of the same type and equal.
+func equalValues(a, b any) bool {
+	a, b = unwrapValue(a), unwrapValue(b)
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case []byte:
+		y, ok := b.([]byte)
+		return ok && bytes.Equal(x, y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Next satisfies the ResultSet interface.
+func (view *DiffView) Next() bool {
+	if view.err != nil {
+		return false
+	}
+	if view.sorted {
+		return view.nextSorted()
+	}
+	for {
+		row, err := view.read(view.b)
+		switch {
+		case err != nil:
+			view.fail(err)
+			return false
+		case row == nil:
+			// removed rows
+			for ; view.pos < len(view.arows); view.pos++ {
+				if !view.matched[view.pos] {
+					view.row = DiffRow{Kind: DiffRemoved, Old: view.arows[view.pos]}
+					view.pos++
+					return true
+				}
+			}
+			return false
+		}
+		k := view.key(row)
+		idx := view.index[k]
+		if len(idx) == 0 {
+			view.row = DiffRow{Kind: DiffAdded, New: row}
+			return true
+		}
+		view.index[k], view.matched[idx[0]] = idx[1:], true
+		if view.change(view.arows[idx[0]], row) {
+			return true
+		}
+	}
+}
+
+// nextSorted reads the next diff row of sorted result sets.
+func (view *DiffView) nextSorted() bool {
+	for {
+		var err error
+		if view.arow == nil {
+			if view.arow, err = view.read(view.a); err != nil {
+				view.fail(err)
+				return false
+			}
+		}
+		if view.brow == nil {
+			if view.brow, err = view.read(view.b); err != nil {
+				view.fail(err)
+				return false
+			}
+		}
+		var c int
+		switch {
+		case view.arow == nil && view.brow == nil:
+			return false
+		case view.arow == nil:
+			c = 1
+		case view.brow == nil:
+			c = -1
+		default:
+			c = view.compareKeys(view.arow, view.brow)
+		}
+		switch {
+		case c < 0:
+			view.row, view.arow = DiffRow{Kind: DiffRemoved, Old: view.arow}, nil
+			return true
+		case c > 0:
+			view.row, view.brow = DiffRow{Kind: DiffAdded, New: view.brow}, nil
+			return true
+		}
+		a, b := view.arow, view.brow
+		view.arow, view.brow = nil, nil
+		if view.change(a, b) {
+			return true
+		}
+	}
+}
+
+// Row returns the current diff row.
+func (view *DiffView) Row() DiffRow {
+	return view.row
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *DiffView) Scan(v ...any) error {
+	vals := make([]any, 0, len(view.cols)+1)
+	vals = append(vals, view.row.Kind.String())
+	switch view.row.Kind {
+	case DiffAdded:
+		vals = append(vals, view.row.New...)
+	case DiffRemoved:
+		vals = append(vals, view.row.Old...)
+	case DiffChanged:
+		for i, z := range view.row.New {
+			if view.row.Changed[i] {
+				z = DiffValue{Old: view.row.Old[i], New: z}
+			}
+			vals = append(vals, z)
+		}
+	}
+	for i := 0; i < len(vals) && i < len(v); i++ {
+		if err := assign(v[i], vals[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *DiffView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	return append([]string{"diff"}, view.cols...), nil
+}
+
+// Close satisfies the ResultSet interface.
+func (view *DiffView) Close() error {
+	err := view.a.Close()
+	if e := view.b.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Err satisfies the ResultSet interface.
+func (view *DiffView) Err() error {
+	return view.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *DiffView) NextResultSet() bool {
+	return false
+}
+
+// rows returns the remaining diff rows, ordered by key.
+func (view *DiffView) rows() ([]DiffRow, error) {
+	var rows []DiffRow
+	for view.Next() {
+		rows = append(rows, view.row)
+	}
+	if err := view.Err(); err != nil {
+		return nil, err
+	}
+	if !view.sorted {
+		key := func(row DiffRow) []any {
+			if row.New != nil {
+				return row.New
+			}
+			return row.Old
+		}
+		slices.SortStableFunc(rows, func(a, b DiffRow) int {
+			return view.compareKeys(key(a), key(b))
+		})
+	}
+	return rows, nil
+}
+
+// EncodeUnified encodes the remaining diff rows to the writer as a unified
+// text diff ordered by key, with changed rows written as a removed and an
+// added line. Values are separated by '|'.
+func (view *DiffView) EncodeUnified(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("--- " + view.labels[0] + "\n+++ " + view.labels[1] + "\n " + strings.Join(view.cols, "|") + "\n"); err != nil {
+		return err
+	}
+	line := func(marker byte, row []any) error {
+		vals, err := view.formatter.Format(row)
+		if err != nil {
+			return err
+		}
+		if err := bw.WriteByte(marker); err != nil {
+			return err
+		}
+		for i, v := range vals {
+			if i != 0 {
+				if err := bw.WriteByte('|'); err != nil {
+					return err
+				}
+			}
+			if v != nil {
+				if _, err := bw.Write(v.Buf); err != nil {
+					return err
+				}
+			}
+		}
+		return bw.WriteByte('\n')
+	}
+	rows, err := view.rows()
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.Old != nil {
+			if err := line('-', row.Old); err != nil {
+				return err
+			}
+		}
+		if row.New != nil {
+			if err := line('+', row.New); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// EncodeJSON encodes the remaining diff rows to the writer as a JSON array of
+// objects, each with the diff marker ("op"), the old and new rows ("old" and
+// "new"), and, for changed rows, the changed column names ("changed").
+func (view *DiffView) EncodeJSON(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	write := func(s string) error {
+		_, err := buf.WriteString(s)
+		return err
+	}
+	object := func(name string, row []any) error {
+		if err := write(`,"` + name + `":{`); err != nil {
+			return err
+		}
+		for i, v := range row {
+			if i != 0 {
+				if err := write(","); err != nil {
+					return err
+				}
+			}
+			if err := writeJSON(buf, view.cols[i]); err != nil {
+				return err
+			}
+			if err := write(":"); err != nil {
+				return err
+			}
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			if err := writeJSON(buf, v); err != nil {
+				return err
+			}
+		}
+		return write("}")
+	}
+	rows, err := view.rows()
+	if err != nil {
+		return err
+	}
+	if err := write("["); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		if i != 0 {
+			if err := write(","); err != nil {
+				return err
+			}
+		}
+		if err := write(`{"op":"` + row.Kind.String() + `"`); err != nil {
+			return err
+		}
+		if row.Old != nil {
+			if err := object("old", row.Old); err != nil {
+				return err
+			}
+		}
+		if row.New != nil {
+			if err := object("new", row.New); err != nil {
+				return err
+			}
+		}
+		if row.Kind == DiffChanged {
+			var changed []string
+			for j, ok := range row.Changed {
+				if ok {
+					changed = append(changed, view.cols[j])
+				}
+			}
+			if err := write(`,"changed":`); err != nil {
+				return err
+			}
+			if err := writeJSON(buf, changed); err != nil {
+				return err
+			}
+		}
+		if err := write("}"); err != nil {
+			return err
+		}
+	}
+	if err := write("]"); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// writeJSON writes the JSON encoding of v to the writer.
+func writeJSON(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,213 @@
+package tblfmt
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/xo/tblfmt/internal"
+)
+
+func TestNewDiffView(t *testing.T) {
+	t.Parallel()
+	rs := func() (ResultSet, ResultSet) {
+		return internal.New([]string{"id", "name", "qty"}, [][]any{
+				{1, "a", 10},
+				{2, "b", 20},
+				{3, "c", nil},
+				{5, "e", 50},
+			}), internal.New([]string{"id", "name", "qty"}, [][]any{
+				{1, "a", 10},
+				{2, "b", 25},
+				{3, "c", 30},
+				{4, "d", 40},
+			})
+	}
+	tests := []struct {
+		sorted bool
+		exp    string
+	}{
+		{false, ` diff | id | name |    qty    
+------+----+------+-----------
+ ~    |  2 | b    | 20 → 25 
+ ~    |  3 | c    | NULL → 30 
+ +    |  4 | d    |        40 
+ -    |  5 | e    |        50 
+(4 rows)
+`},
+		{true, ` diff | id | name |    qty    
+------+----+------+-----------
+ ~    |  2 | b    | 20 → 25 
+ ~    |  3 | c    | NULL → 30 
+ +    |  4 | d    |        40 
+ -    |  5 | e    |        50 
+(4 rows)
+`},
+	}
+	for i, test := range tests {
+		a, b := rs()
+		view, err := NewDiffView(a, b, WithKeyColumns("id"), WithSortedInputs(test.sorted))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := EncodeTable(buf, view); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if actual := buf.String(); actual != test.exp {
+			t.Errorf("test %d expected:\n%q\n---\ngot:\n%q", i, test.exp, actual)
+		}
+	}
+}
+
+func TestDiffViewChanged(t *testing.T) {
+	t.Parallel()
+	for _, sorted := range []bool{false, true} {
+		a := internal.New([]string{"id", "v"}, [][]any{{1, "1.0"}, {2, "007"}, {3, 1}, {4, "x"}})
+		b := internal.New([]string{"id", "v"}, [][]any{{1, "1"}, {2, "7"}, {3, 1.0}, {4, "x"}})
+		view, err := NewDiffView(a, b, WithKeyColumns("id"), WithSortedInputs(sorted))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var n int
+		for view.Next() {
+			if row := view.Row(); row.Kind != DiffChanged || !row.Changed[1] {
+				t.Errorf("sorted %t row %d expected changed value, got: %v", sorted, n, row)
+			}
+			n++
+		}
+		if n != 3 {
+			t.Errorf("sorted %t expected 3 changed rows, got: %d", sorted, n)
+		}
+	}
+}
+
+func TestDiffViewKeys(t *testing.T) {
+	t.Parallel()
+	for _, sorted := range []bool{false, true} {
+		a := internal.New([]string{"id", "v"}, [][]any{{"1", "a"}, {2, "b"}})
+		b := internal.New([]string{"id", "v"}, [][]any{{1, "a"}, {2, "b"}})
+		view, err := NewDiffView(a, b, WithKeyColumns("id"), WithSortedInputs(sorted))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var kinds []DiffKind
+		for view.Next() {
+			kinds = append(kinds, view.Row().Kind)
+		}
+		if len(kinds) != 2 || !slices.Contains(kinds, DiffAdded) || !slices.Contains(kinds, DiffRemoved) {
+			t.Errorf("sorted %t expected an added and removed row, got: %v", sorted, kinds)
+		}
+	}
+}
+
+func TestDiffViewHighlight(t *testing.T) {
+	t.Parallel()
+	a := internal.New([]string{"id", "v"}, [][]any{{1, nil}})
+	b := internal.New([]string{"id", "v"}, [][]any{{1, "x"}})
+	view, err := NewDiffView(a, b, WithKeyColumns("id"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, view, WithFormatterOptions(WithNullText("∅"), WithDiffHighlight(true))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := " diff | id |   v   \n" +
+		"------+----+-------\n" +
+		" ~    |  1 | " + diffOldColor + "∅" + resetColor + " → " + diffNewColor + "x" + resetColor + " \n" +
+		"(1 row)\n"
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestDiffViewEncode(t *testing.T) {
+	t.Parallel()
+	rs := func() (ResultSet, ResultSet) {
+		return internal.New([]string{"id", "v"}, [][]any{
+				{1, "x"},
+				{2, "y"},
+			}), internal.New([]string{"id", "v"}, [][]any{
+				{1, "z"},
+				{3, "w"},
+			})
+	}
+	a, b := rs()
+	view, err := NewDiffView(a, b, WithKeyColumns("id"), WithDiffLabels("staging", "prod"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `--- staging
++++ prod
+ id|v
+-1|x
++1|z
+-2|y
++3|w
+`
+	buf := new(bytes.Buffer)
+	if err := view.EncodeUnified(buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%s\n---\ngot:\n%s", exp, actual)
+	}
+	a, b = rs()
+	if view, err = NewDiffView(a, b, WithKeyColumns("id"), WithSortedInputs(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp = `[{"op":"~","old":{"id":1,"v":"x"},"new":{"id":1,"v":"z"},"changed":["v"]},` +
+		`{"op":"-","old":{"id":2,"v":"y"}},{"op":"+","new":{"id":3,"v":"w"}}]`
+	buf.Reset()
+	if err := view.EncodeJSON(buf); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%s\n---\ngot:\n%s", exp, actual)
+	}
+	a, _ = rs()
+	if _, err := NewDiffView(a, internal.New([]string{"id"}, nil)); err != ErrDiffColumnsDoNotMatch {
+		t.Errorf("expected error %v, got: %v", ErrDiffColumnsDoNotMatch, err)
+	}
+	a, b = rs()
+	if _, err := NewDiffView(a, b); err != ErrDiffNoKeyColumns {
+		t.Errorf("expected error %v, got: %v", ErrDiffNoKeyColumns, err)
+	}
+}
+
+func TestDiffViewEncodeWriteError(t *testing.T) {
+	t.Parallel()
+	var vals [][]any
+	for i := range 1000 {
+		vals = append(vals, []any{i, "abcdefghijklmnopqrstuvwxyz"})
+	}
+	for i, encode := range []func(*DiffView, io.Writer) error{
+		(*DiffView).EncodeUnified,
+		(*DiffView).EncodeJSON,
+	} {
+		view, err := NewDiffView(internal.New([]string{"id", "v"}, nil), internal.New([]string{"id", "v"}, vals), WithKeyColumns("id"))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		w := &errWriter{}
+		if err := encode(view, w); err != io.ErrClosedPipe {
+			t.Errorf("test %d expected error %v, got: %v", i, io.ErrClosedPipe, err)
+		}
+		if w.n != 1 {
+			t.Errorf("test %d expected 1 write, got: %d", i, w.n)
+		}
+	}
+}
+
+// errWriter is a writer that always fails.
+type errWriter struct {
+	n int
+}
+
+// Write satisfies the io.Writer interface.
+func (w *errWriter) Write([]byte) (int, error) {
+	w.n++
+	return 0, io.ErrClosedPipe
+}
@@ -3,11 +3,14 @@ package tblfmt
 import (
 	"bufio"
 	"bytes"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os/exec"
+	"reflect"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -56,6 +59,13 @@ type TableEncoder struct {
 	// maxWidths are calculated max column widths. Max widths are at least as
 	// wide as user-supplied widths
 	maxWidths []int
+	// decimalAlign toggles aligning numeric values on their decimal
+	// separator.
+	decimalAlign bool
+	// intWidths and fracWidths are the calculated max integer and fraction
+	// widths of the numeric values of each column, when aligning numeric
+	// values on their decimal separator.
+	intWidths, fracWidths []int
 	// minExpandWidth of the table required to switch to the ExpandedEncoder
 	// zero disables switching
 	minExpandWidth int
@@ -74,6 +84,24 @@ type TableEncoder struct {
 	headerTransformer Transformer
 	// columnTypes is used to build column types for a result set.
 	columnTypes func(ResultSet, []any, int) error
+	// groupBy is the column used to group rows.
+	groupBy string
+	// subtotals are the columns to subtotal for each group.
+	subtotals []string
+	// subtotalLabel is the key column value of subtotal rows.
+	subtotalLabel string
+	// group is the group state for the result set being encoded.
+	group *grouper
+	// repeated are the columns to suppress repeated values in.
+	repeated []string
+	// merge toggles drawing suppressed repeated values as merged cells.
+	merge bool
+	// repeat is the repeated value state for the result set being encoded.
+	repeat *repeater
+	// headerGroups are the header groups.
+	headerGroups []HeaderGroup
+	// spans are the formatted header groups.
+	spans []HeaderSpan
 	// w is the undelying writer
 	w *bufio.Writer
 }
@@ -93,6 +121,7 @@ func NewTableEncoder(resultSet ResultSet, opts ...Option) (Encoder, error) {
 		empty: &Value{
 			Tabs: make([][][2]int, 1),
 		},
+		subtotalLabel: "subtotal",
 	}
 	// apply options
 	for _, o := range opts {
@@ -135,16 +164,32 @@ func (enc *TableEncoder) Encode(w io.Writer) error {
 	case clen == 0:
 		return ErrResultSetHasNoColumns
 	}
+	enc.formatter = formatterFor(enc.formatter, enc.resultSet, cols)
 	// setup offsets, widths
 	enc.offsets = make([]int, clen)
 	wroteHeader := enc.skipHeader
 	// default to user-supplied widths
 	enc.maxWidths = make([]int, clen)
 	copy(enc.maxWidths, enc.widths)
+	enc.intWidths, enc.fracWidths = make([]int, clen), make([]int, clen)
 	enc.headers, err = enc.formatter.Header(cols)
 	if err != nil {
 		return err
 	}
+	// group and repeat suppressed cells share a blank value, so that both
+	// are drawn as merged cells
+	blank := &Value{
+		Tabs: make([][][2]int, 1),
+	}
+	if enc.group, err = newGrouper(cols, enc.groupBy, enc.subtotals, enc.subtotalLabel, blank); err != nil {
+		return err
+	}
+	if enc.repeat, err = newRepeater(cols, enc.repeated, blank); err != nil {
+		return err
+	}
+	if enc.spans, err = buildHeaderSpans(enc.formatter, enc.headerGroups, clen); err != nil {
+		return err
+	}
 	var cmd *exec.Cmd
 	var cmdBuf io.WriteCloser
 	for {
@@ -167,6 +212,7 @@ func (enc *TableEncoder) Encode(w io.Writer) error {
 			}
 			exp.offsets = make([]int, 2)
 			exp.maxWidths = make([]int, 2)
+			exp.intWidths, exp.fracWidths = make([]int, 2), make([]int, 2)
 			exp.headers, err = exp.formatter.Header(cols)
 			if err != nil {
 				return err
@@ -208,6 +254,9 @@ func (enc *TableEncoder) Encode(w io.Writer) error {
 			enc.divider(enc.rowStyle(enc.lineStyle.End))
 		}
 	}
+	if err := notifyMore(enc.w, enc.resultSet, enc.newline); err != nil {
+		return checkErr(err, cmd)
+	}
 	// add summary
 	if err := summarize(enc.w, enc.summary, enc.scanCount); err != nil {
 		return err
@@ -226,7 +275,18 @@ func (enc *TableEncoder) encodeVals(vals [][]*Value) error {
 	rs := enc.rowStyle(enc.lineStyle.Row)
 	// print buffered vals
 	for i := range vals {
+		switch {
+		case enc.group.kind(i) == rowSubtotal, enc.group.kind(i) == rowGroupStart:
+			// draw divider before subtotals and between groups
+			enc.divider(enc.rowStyle(enc.lineStyle.Mid))
+		case enc.merge && enc.repeat.wrote && (i != 0 || enc.border < 2):
+			// draw divider between rows, leaving out merged cells
+			enc.mergeDivider(vals[i])
+		}
 		enc.row(vals[i], rs)
+		if enc.repeat != nil {
+			enc.repeat.wrote = true
+		}
 		if i+1%1000 == 0 {
 			// check error every 1k rows
 			if err := enc.w.Flush(); err != nil {
@@ -259,9 +319,11 @@ func (enc *TableEncoder) nextResults() ([][]*Value, error) {
 	if enc.count != 0 {
 		vals = make([][]*Value, 0, enc.count)
 	}
+	enc.group.reset()
 	// read to count (or all)
 	var i int
 	var r []any
+	var more bool
 	for enc.resultSet.Next() {
 		if i == 0 {
 			// set up storage for results
@@ -275,12 +337,37 @@ func (enc *TableEncoder) nextResults() ([][]*Value, error) {
 		if err != nil {
 			return vals, err
 		}
+		if enc.group != nil {
+			sub, err := enc.group.add(enc.formatter, r, v)
+			if err != nil {
+				return vals, err
+			}
+			if sub != nil {
+				vals = append(vals, sub)
+			}
+			// show repeated values again at the start of each group
+			if enc.group.kind(len(vals)) == rowGroupStart {
+				enc.repeat.reset()
+			}
+		}
+		enc.repeat.suppress(v)
 		vals, i = append(vals, v), i+1
 		// read by batches of enc.count rows
 		if enc.count != 0 && i%enc.count == 0 {
+			more = true
 			break
 		}
 	}
+	// subtotal the last group
+	if enc.group != nil && !more {
+		sub, err := enc.group.flush(enc.formatter)
+		if err != nil {
+			return vals, err
+		}
+		if sub != nil {
+			vals = append(vals, sub)
+		}
+	}
 	return vals, enc.resultSet.Err()
 }
 
@@ -304,6 +391,11 @@ func (enc *TableEncoder) calcWidth(vals [][]*Value) {
 				cell = enc.empty
 			}
 			enc.maxWidths[i] = max(enc.maxWidths[i], cell.MaxWidth(offset, enc.tab))
+			enc.calcDecimalWidth(i, cell)
+		}
+		// widen the last column of a header group to fit the group name
+		if start, v := enc.spanEnding(i); v != nil {
+			enc.maxWidths[i] += max(0, v.MaxWidth(0, enc.tab)-enc.spanWidth(start, i))
 		}
 		// add column width, and one space for newline indicator
 		offset += enc.maxWidths[i]
@@ -313,6 +405,23 @@ func (enc *TableEncoder) calcWidth(vals [][]*Value) {
 	}
 }
 
+// calcDecimalWidth calculates the integer and fraction widths of column i when
+// aligning numeric values on their decimal separator, widening the column as
+// needed.
+func (enc *TableEncoder) calcDecimalWidth(i int, v *Value) {
+	if !enc.decimalAligned(v) {
+		return
+	}
+	enc.intWidths[i] = max(enc.intWidths[i], v.Width-v.Fraction)
+	enc.fracWidths[i] = max(enc.fracWidths[i], v.Fraction)
+	enc.maxWidths[i] = max(enc.maxWidths[i], enc.intWidths[i]+enc.fracWidths[i])
+}
+
+// decimalAligned returns true when v is aligned on its decimal separator.
+func (enc *TableEncoder) decimalAligned(v *Value) bool {
+	return enc.decimalAlign && v.Numeric && len(v.Newlines) == 0
+}
+
 func (enc *TableEncoder) header() {
 	rs := enc.rowStyle(enc.lineStyle.Row)
 	if enc.title != nil && enc.title.Width != 0 {
@@ -322,7 +431,12 @@ func (enc *TableEncoder) header() {
 	}
 	// draw top border
 	if enc.border >= 2 && !enc.inline {
-		enc.divider(enc.rowStyle(enc.lineStyle.Top))
+		enc.spanDivider(enc.rowStyle(enc.lineStyle.Top), enc.joined())
+	}
+	// draw header groups
+	if enc.spans != nil && !enc.inline {
+		enc.groupRow(rs)
+		enc.groupDivider()
 	}
 	// draw the header row with top border style
 	if enc.inline {
@@ -389,6 +503,205 @@ func (enc *TableEncoder) divider(rs rowStyle) {
 	_, _ = enc.w.Write(rs.right)
 }
 
+// mergeDivider draws a divider above a row, leaving out the divider for
+// suppressed cells so that they appear merged with the cell above.
+func (enc *TableEncoder) mergeDivider(vals []*Value) {
+	open := make([]bool, len(enc.maxWidths))
+	for i := range open {
+		open[i] = i < len(vals) && enc.repeat.suppressed(vals[i])
+	}
+	enc.openDivider(open, nil)
+}
+
+// openDivider draws a mid divider, leaving out the divider for open columns.
+// When joined[i] is true, columns i and i+1 are below a single cell spanning
+// both columns.
+func (enc *TableEncoder) openDivider(open, joined []bool) {
+	top, mid, row := enc.lineStyle.Top, enc.lineStyle.Mid, enc.lineStyle.Row
+	n := len(enc.maxWidths)
+	style := func(i int) rowStyle {
+		if open[i] {
+			return enc.rowStyle(row)
+		}
+		return enc.rowStyle(mid)
+	}
+	spacer := func(i int) []byte {
+		return bytes.Repeat(style(i).filler, runewidth.RuneWidth(row[1]))
+	}
+	// left
+	if enc.border > 1 {
+		if open[0] {
+			_, _ = enc.w.WriteString(string(row[0]))
+		} else {
+			_, _ = enc.w.WriteString(string(mid[0]))
+		}
+	}
+	if enc.border > 0 {
+		_, _ = enc.w.Write(spacer(0))
+	}
+	for i, width := range enc.maxWidths {
+		rs := style(i)
+		// column
+		_, _ = enc.w.Write(bytes.Repeat(rs.filler, width))
+		// line feed indicator
+		if rs.hasWrapping && enc.border >= 1 {
+			_, _ = enc.w.Write(rs.filler)
+		}
+		if i == n-1 {
+			break
+		}
+		// middle separator, joining the columns below a single cell when
+		// there is no border
+		if enc.border < 1 {
+			if joined != nil && joined[i] && !open[i] && !open[i+1] {
+				_, _ = enc.w.Write(rs.filler)
+			} else {
+				_, _ = enc.w.WriteString(" ")
+			}
+			continue
+		}
+		switch {
+		case open[i] && open[i+1]:
+			_, _ = enc.w.WriteString(string(row[2]))
+		case open[i]:
+			_, _ = enc.w.WriteString(string(mid[0]))
+		case open[i+1]:
+			_, _ = enc.w.WriteString(string(mid[3]))
+		case joined != nil && joined[i]:
+			_, _ = enc.w.WriteString(string(top[2]))
+		default:
+			_, _ = enc.w.WriteString(string(mid[2]))
+		}
+		_, _ = enc.w.Write(spacer(i + 1))
+	}
+	// right
+	if enc.border > 1 {
+		if open[n-1] {
+			_, _ = enc.w.WriteString(string(row[3]))
+		} else {
+			_, _ = enc.w.WriteString(string(mid[3]))
+		}
+	}
+	_, _ = enc.w.Write(enc.newline)
+}
+
+// spanDivider draws a divider, leaving out the middle separator between
+// columns i and i+1 when joined[i] is true.
+func (enc *TableEncoder) spanDivider(rs rowStyle, joined []bool) {
+	// left
+	_, _ = enc.w.Write(rs.left)
+	for i, width := range enc.maxWidths {
+		// column
+		_, _ = enc.w.Write(bytes.Repeat(rs.filler, width))
+		// line feed indicator
+		if rs.hasWrapping && enc.border >= 1 {
+			_, _ = enc.w.Write(rs.filler)
+		}
+		// middle separator
+		switch {
+		case i == len(enc.maxWidths)-1:
+		case joined[i]:
+			_, _ = enc.w.Write(bytes.Repeat(rs.filler, runewidth.StringWidth(string(rs.middle))))
+		default:
+			_, _ = enc.w.Write(rs.middle)
+		}
+	}
+	// right
+	_, _ = enc.w.Write(rs.right)
+}
+
+// groupRow draws the header group row.
+func (enc *TableEncoder) groupRow(rs rowStyle) {
+	// left
+	_, _ = enc.w.Write(rs.left)
+	var i int
+	for k, span := range enc.spans {
+		end := i + span.Span - 1
+		width := enc.spanWidth(i, end)
+		v := span.Value
+		if v == nil {
+			v = enc.empty
+		}
+		align, padding := v.Align, width-v.Width
+		// no trailing padding for last cell if no border
+		if enc.border <= 1 && k == len(enc.spans)-1 {
+			switch align {
+			case AlignLeft:
+				padding = 0
+			case AlignCenter:
+				align, padding = AlignRight, padding/2
+			}
+		}
+		enc.writeAligned(v.Buf, rs.filler, align, padding)
+		// newline wrap value
+		if rs.hasWrapping {
+			_, _ = enc.w.Write(rs.filler)
+		}
+		// middle separator
+		if end != len(enc.maxWidths)-1 && enc.border >= 1 {
+			_, _ = enc.w.Write(rs.middle)
+		}
+		i = end + 1
+	}
+	// right
+	_, _ = enc.w.Write(rs.right)
+}
+
+// groupDivider draws the divider between the header group row and the header
+// row, leaving out the divider below ungrouped columns.
+func (enc *TableEncoder) groupDivider() {
+	open := make([]bool, len(enc.maxWidths))
+	var i int
+	for _, span := range enc.spans {
+		for j := i; j < i+span.Span; j++ {
+			open[j] = span.Value == nil
+		}
+		i += span.Span
+	}
+	enc.openDivider(open, enc.joined())
+}
+
+// joined returns the columns joined by a header group.
+func (enc *TableEncoder) joined() []bool {
+	joined := make([]bool, len(enc.maxWidths))
+	var i int
+	for _, span := range enc.spans {
+		for j := i; j < i+span.Span-1; j++ {
+			joined[j] = true
+		}
+		i += span.Span
+	}
+	return joined
+}
+
+// spanEnding returns the start column and value of the header group ending at
+// column i.
+func (enc *TableEncoder) spanEnding(i int) (int, *Value) {
+	var start int
+	for _, span := range enc.spans {
+		if start+span.Span-1 == i {
+			return start, span.Value
+		}
+		start += span.Span
+	}
+	return 0, nil
+}
+
+// spanWidth returns the width of the columns start through end, including the
+// separators between them.
+func (enc *TableEncoder) spanWidth(start, end int) int {
+	rs := enc.rowStyle(enc.lineStyle.Row)
+	gap := runewidth.StringWidth(string(rs.middle))
+	if rs.hasWrapping && enc.border != 0 {
+		gap++
+	}
+	width := (end - start) * gap
+	for i := start; i <= end; i++ {
+		width += enc.maxWidths[i]
+	}
+	return width
+}
+
 // tableWidth calculates total table width.
 func (enc *TableEncoder) tableWidth() int {
 	rs := enc.rowStyle(enc.lineStyle.Mid)
@@ -417,6 +730,10 @@ func (enc *TableEncoder) tableHeight(rows [][]*Value) int {
 	}
 	// header
 	height++
+	// header groups
+	if enc.spans != nil && !enc.inline {
+		height += 2
+	}
 	// mid divider
 	if enc.inline {
 		height++
@@ -433,6 +750,13 @@ func (enc *TableEncoder) tableHeight(rows [][]*Value) int {
 		}
 		height += largest
 	}
+	// row, subtotal, and group dividers
+	for i := range rows {
+		switch kind := enc.group.kind(i); {
+		case enc.merge && i != 0, kind == rowSubtotal, kind == rowGroupStart:
+			height++
+		}
+	}
 	// end border
 	if enc.border >= 2 {
 		height++
@@ -474,10 +798,15 @@ func (enc *TableEncoder) row(vals []*Value, rs rowStyle) {
 				}
 				padding := enc.maxWidths[i] - width
 				// no padding for last cell if no border and aligned left
-				if enc.border <= 1 && v.Align == AlignLeft && i == len(vals)-1 && (!rs.hasWrapping || l >= len(v.Newlines)) {
-					padding = 0
+				last := enc.border <= 1 && v.Align == AlignLeft && i == len(vals)-1 && (!rs.hasWrapping || l >= len(v.Newlines))
+				switch {
+				case enc.decimalAligned(v):
+					enc.writeDecimalAligned(v, rs.filler, i, last)
+				case last:
+					enc.writeAligned(v.Buf[start:end], rs.filler, v.Align, 0)
+				default:
+					enc.writeAligned(v.Buf[start:end], rs.filler, v.Align, padding)
 				}
-				enc.writeAligned(v.Buf[start:end], rs.filler, v.Align, padding)
 			} else if enc.border > 1 || i != len(vals)-1 {
 				_, _ = enc.w.Write(bytes.Repeat(rs.filler, enc.maxWidths[i]))
 			}
@@ -532,12 +861,326 @@ func (enc *TableEncoder) writeAligned(b, filler []byte, a Align, padding int) {
 	}
 }
 
+// writeDecimalAligned writes a numeric value of column i aligned on its
+// decimal separator, with the numeric values of the column aligned as a block
+// using the value's alignment.
+func (enc *TableEncoder) writeDecimalAligned(v *Value, filler []byte, i int, last bool) {
+	var left int
+	switch padding := enc.maxWidths[i] - enc.intWidths[i] - enc.fracWidths[i]; v.Align {
+	case AlignRight:
+		left = padding
+	case AlignCenter:
+		left = padding / 2
+	}
+	left += enc.intWidths[i] - (v.Width - v.Fraction)
+	right := enc.maxWidths[i] - v.Width - left
+	if last {
+		right = 0
+	}
+	if left > 0 {
+		_, _ = enc.w.Write(bytes.Repeat(filler, left))
+	}
+	_, _ = enc.w.Write(v.Buf)
+	if right > 0 {
+		_, _ = enc.w.Write(bytes.Repeat(filler, right))
+	}
+}
+
 // rowStyle is the row style for a row, as arrays of bytes to print.
 type rowStyle struct {
 	left, right, middle, filler, wrapper []byte
 	hasWrapping                          bool
 }
 
+// rowKind is the kind of a buffered row.
+type rowKind int
+
+// Row kinds.
+const (
+	rowData rowKind = iota
+	rowGroupFirst
+	rowGroupStart
+	rowSubtotal
+)
+
+// grouper tracks the group state of rows in a result set grouped by a key
+// column.
+type grouper struct {
+	// n is the number of columns.
+	n int
+	// index is the group by column index.
+	index int
+	// cols are the subtotal column indexes.
+	cols []int
+	// label is the key column value of subtotal rows.
+	label string
+	// totals are the running subtotals for the current group.
+	totals []subtotal
+	// key is the current group key.
+	key string
+	// started indicates the first group has started.
+	started bool
+	// kinds are the row kinds for the current batch of rows.
+	kinds []rowKind
+	// blank is the value used for suppressed cells.
+	blank *Value
+}
+
+// newGrouper creates a grouper for the columns, when groupBy is not empty,
+// using blank for suppressed key values.
+func newGrouper(cols []string, groupBy string, subtotals []string, label string, blank *Value) (*grouper, error) {
+	if groupBy == "" {
+		return nil, nil
+	}
+	index := indexOf(cols, groupBy)
+	if index == -1 {
+		return nil, ErrGroupByColumnNotInResult
+	}
+	g := &grouper{
+		n:      len(cols),
+		index:  index,
+		label:  label,
+		totals: make([]subtotal, len(subtotals)),
+		blank:  blank,
+	}
+	for _, s := range subtotals {
+		i := indexOf(cols, s)
+		if i == -1 {
+			return nil, ErrSubtotalColumnNotInResult
+		}
+		g.cols = append(g.cols, i)
+	}
+	return g, nil
+}
+
+// reset resets the row kinds for a new batch of rows.
+func (g *grouper) reset() {
+	if g != nil {
+		g.kinds = g.kinds[:0]
+	}
+}
+
+// kind returns the kind of row i in the current batch.
+func (g *grouper) kind(i int) rowKind {
+	if g == nil || i >= len(g.kinds) {
+		return rowData
+	}
+	return g.kinds[i]
+}
+
+// add adds a scanned row, suppressing the key value when it repeats the key
+// of the preceding row. Returns the subtotal row for the preceding group when
+// the key changes.
+func (g *grouper) add(formatter Formatter, r []any, v []*Value) ([]*Value, error) {
+	var key string
+	if v[g.index] != nil {
+		key = v[g.index].String()
+	}
+	var sub []*Value
+	kind := rowData
+	switch {
+	case !g.started:
+		kind = rowGroupFirst
+	case key != g.key:
+		var err error
+		if sub, err = g.flush(formatter); err != nil {
+			return nil, err
+		}
+		kind = rowGroupStart
+	default:
+		v[g.index] = g.blank
+	}
+	g.kinds = append(g.kinds, kind)
+	g.started, g.key = true, key
+	for i, j := range g.cols {
+		g.totals[i].add(r[j])
+	}
+	return sub, nil
+}
+
+// flush returns the subtotal row for the current group, ending the group.
+// Returns nil when there are no subtotal columns or no group has started.
+func (g *grouper) flush(formatter Formatter) ([]*Value, error) {
+	if len(g.cols) == 0 || !g.started {
+		return nil, nil
+	}
+	g.started = false
+	row := make([]any, g.n)
+	for i := range row {
+		row[i] = new(any)
+	}
+	*(row[g.index].(*any)) = g.label
+	for i, j := range g.cols {
+		*(row[j].(*any)) = g.totals[i].value()
+		g.totals[i] = subtotal{}
+	}
+	vals, err := formatter.Format(row)
+	if err != nil {
+		return nil, err
+	}
+	g.kinds = append(g.kinds, rowSubtotal)
+	return vals, nil
+}
+
+// repeater suppresses values that are equal to the value in the row above.
+type repeater struct {
+	// cols are the column indexes to suppress repeated values in.
+	cols []int
+	// prev are the previous row's values.
+	prev []string
+	// started indicates a row has been seen.
+	started bool
+	// wrote indicates a row has been written.
+	wrote bool
+	// blank is the value used for suppressed cells.
+	blank *Value
+}
+
+// newRepeater creates a repeater for the columns, when repeated is not empty,
+// using blank for suppressed values.
+func newRepeater(cols []string, repeated []string, blank *Value) (*repeater, error) {
+	if len(repeated) == 0 {
+		return nil, nil
+	}
+	r := &repeater{
+		prev:  make([]string, len(repeated)),
+		blank: blank,
+	}
+	for _, s := range repeated {
+		i := indexOf(cols, s)
+		if i == -1 {
+			return nil, ErrRepeatedColumnNotInResult
+		}
+		r.cols = append(r.cols, i)
+	}
+	return r, nil
+}
+
+// reset resets the repeater so that the next row's values are not
+// suppressed.
+func (r *repeater) reset() {
+	if r != nil {
+		r.started = false
+	}
+}
+
+// suppress replaces values in v equal to the previous row's values with a
+// blank value. Columns are treated as a hierarchy: a value is only suppressed
+// when the values of all preceding columns were also suppressed.
+func (r *repeater) suppress(v []*Value) {
+	if r == nil {
+		return
+	}
+	repeated := r.started
+	for i, j := range r.cols {
+		// already suppressed, as a repeated group key
+		if v[j] == r.blank {
+			continue
+		}
+		var s string
+		if v[j] != nil {
+			s = v[j].String()
+		}
+		if repeated = repeated && s == r.prev[i]; repeated {
+			v[j] = r.blank
+		}
+		r.prev[i] = s
+	}
+	r.started = true
+}
+
+// suppressed returns true when v is a suppressed value.
+func (r *repeater) suppressed(v *Value) bool {
+	return r != nil && v == r.blank
+}
+
+// spans returns the row spans for rows, where a suppressed cell is merged
+// with the cell above it. A span of 0 indicates the cell is merged.
+func (r *repeater) spans(rows [][]*Value) [][]int {
+	if r == nil {
+		return nil
+	}
+	spans := make([][]int, len(rows))
+	anchors := make([]int, len(r.cols))
+	for i, row := range rows {
+		spans[i] = make([]int, len(row))
+		for j := range row {
+			spans[i][j] = 1
+		}
+		for k, j := range r.cols {
+			if i != 0 && r.suppressed(row[j]) {
+				spans[i][j] = 0
+				spans[anchors[k]][j]++
+				continue
+			}
+			anchors[k] = i
+		}
+	}
+	return spans
+}
+
+// subtotal is a running subtotal of numeric values.
+type subtotal struct {
+	i       int64
+	f       float64
+	isFloat bool
+	valid   bool
+}
+
+// add adds v to the subtotal. Non-numeric values are ignored.
+func (t *subtotal) add(v any) {
+	switch z := toNumber(v).(type) {
+	case int64:
+		t.i, t.f = t.i+z, t.f+float64(z)
+	case float64:
+		t.f, t.isFloat = t.f+z, true
+	default:
+		return
+	}
+	t.valid = true
+}
+
+// toNumber converts v to an int64 or float64, unwrapping driver.Valuer values
+// and parsing strings and bytes. Returns nil when v is not numeric.
+func toNumber(v any) any {
+	v = unwrapValue(v)
+	switch z := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		v = string(z)
+	}
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		return nil
+	}
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		return val.Float()
+	}
+	return nil
+}
+
+// value returns the subtotal value, or nil when no numeric values were added.
+func (t subtotal) value() any {
+	switch {
+	case !t.valid:
+		return nil
+	case t.isFloat:
+		return t.f
+	}
+	return t.i
+}
+
 // ExpandedEncoder is a buffered, lookahead expanded table encoder for result sets.
 type ExpandedEncoder struct {
 	TableEncoder
@@ -545,12 +1188,13 @@ type ExpandedEncoder struct {
 
 // NewExpandedEncoder creates a new expanded table encoder using the provided options.
 func NewExpandedEncoder(resultSet ResultSet, opts ...Option) (Encoder, error) {
+	// default formatter, before options so formatter options are kept
+	opts = append([]Option{WithFormatter(NewEscapeFormatter())}, opts...)
 	tableEnc, err := NewTableEncoder(resultSet, opts...)
 	if err != nil {
 		return nil, err
 	}
 	t := tableEnc.(*TableEncoder)
-	t.formatter = NewEscapeFormatter()
 	if !t.isCustomSummary {
 		t.summary = nil
 	}
@@ -577,13 +1221,20 @@ func (enc *ExpandedEncoder) Encode(w io.Writer) error {
 	case clen == 0:
 		return ErrResultSetHasNoColumns
 	}
+	enc.formatter = formatterFor(enc.formatter, enc.resultSet, cols)
 	// setup offsets, widths
 	enc.offsets = make([]int, 2)
 	enc.maxWidths = make([]int, 2)
+	enc.intWidths, enc.fracWidths = make([]int, 2), make([]int, 2)
 	enc.headers, err = enc.formatter.Header(cols)
 	if err != nil {
 		return err
 	}
+	if enc.group, err = newGrouper(cols, enc.groupBy, enc.subtotals, enc.subtotalLabel, &Value{
+		Tabs: make([][][2]int, 1),
+	}); err != nil {
+		return err
+	}
 	var cmd *exec.Cmd
 	var cmdBuf io.WriteCloser
 	wroteTitle := enc.skipHeader
@@ -620,6 +1271,9 @@ func (enc *ExpandedEncoder) Encode(w io.Writer) error {
 			return checkErr(err, cmd)
 		}
 	}
+	if err := notifyMore(enc.w, enc.resultSet, enc.newline); err != nil {
+		return checkErr(err, cmd)
+	}
 	// add summary
 	if err := summarize(w, enc.summary, enc.scanCount); err != nil {
 		return err
@@ -637,8 +1291,23 @@ func (enc *ExpandedEncoder) Encode(w io.Writer) error {
 func (enc *ExpandedEncoder) encodeVals(vals [][]*Value) error {
 	rs := enc.rowStyle(enc.lineStyle.Row)
 	// print buffered vals
+	var n int
 	for i := range vals {
-		enc.record(i, vals[i], rs)
+		switch kind := enc.group.kind(i); kind {
+		case rowSubtotal:
+			enc.record(enc.subtotalHeader(), false, vals[i], rs)
+		case rowGroupFirst, rowGroupStart:
+			header := enc.groupHeader(n, vals[i])
+			if enc.skipHeader {
+				// draw the group header without the record header
+				enc.recordLine(header, kind == rowGroupFirst)
+			}
+			enc.record(header, kind == rowGroupFirst, vals[i], rs)
+			n++
+		default:
+			enc.record(enc.recordHeader(n), n == 0 && enc.group == nil, vals[i], rs)
+			n++
+		}
 		if i+1%1000 == 0 {
 			// check error every 1k rows
 			if err := enc.w.Flush(); err != nil {
@@ -686,12 +1355,18 @@ func (enc *ExpandedEncoder) calcWidth(vals [][]*Value) {
 	enc.offsets[1] = offset
 	// second column is any value from any row but no less than the record header
 	enc.maxWidths[1] = max(0, len(enc.recordHeader(len(vals)-1))-enc.maxWidths[0]-mw-1)
-	for _, row := range vals {
+	for i, row := range vals {
+		switch enc.group.kind(i) {
+		case rowGroupFirst, rowGroupStart:
+			width := runewidth.StringWidth(enc.groupHeader(len(vals)-1, row))
+			enc.maxWidths[1] = max(enc.maxWidths[1], width-enc.maxWidths[0]-mw-1)
+		}
 		for _, cell := range row {
 			if cell == nil {
 				cell = enc.empty
 			}
 			enc.maxWidths[1] = max(enc.maxWidths[1], cell.MaxWidth(offset, enc.tab))
+			enc.calcDecimalWidth(1, cell)
 		}
 	}
 }
@@ -702,9 +1377,16 @@ func (enc *ExpandedEncoder) tableHeight(rows [][]*Value) int {
 	if enc.title != nil && enc.title.Width != 0 {
 		height += strings.Count(string(enc.title.Buf), "\n")
 	}
-	for _, row := range rows {
+	for i, row := range rows {
 		// header
 		height++
+		// group header, drawn separately when record headers are skipped
+		switch enc.group.kind(i) {
+		case rowGroupFirst, rowGroupStart:
+			if enc.skipHeader {
+				height++
+			}
+		}
 		for _, cell := range row {
 			if cell == nil {
 				cell = enc.empty
@@ -723,26 +1405,10 @@ func (enc *ExpandedEncoder) tableHeight(rows [][]*Value) int {
 	return height
 }
 
-func (enc *ExpandedEncoder) record(i int, vals []*Value, rs rowStyle) {
+func (enc *ExpandedEncoder) record(header string, first bool, vals []*Value, rs rowStyle) {
 	if !enc.skipHeader {
 		// write record header as a single record
-		headerRS := rs
-		header := enc.recordHeader(i)
-		if enc.border != 0 {
-			headerRS = enc.rowStyle(enc.lineStyle.Top)
-			if i != 0 {
-				headerRS = enc.rowStyle(enc.lineStyle.Mid)
-			}
-		}
-		_, _ = enc.w.Write(headerRS.left)
-		_, _ = enc.w.WriteString(header)
-		padding := enc.maxWidths[0] + enc.maxWidths[1] + runewidth.StringWidth(string(headerRS.middle))*2 - len(header) - 1
-		if padding > 0 {
-			_, _ = enc.w.Write(bytes.Repeat(headerRS.filler, padding))
-		}
-		// write newline wrap value
-		_, _ = enc.w.Write(headerRS.filler)
-		_, _ = enc.w.Write(headerRS.right)
+		enc.recordLine(header, first)
 	}
 	// write each value with column name in first col
 	for j, v := range vals {
@@ -753,6 +1419,27 @@ func (enc *ExpandedEncoder) record(i int, vals []*Value, rs rowStyle) {
 	}
 }
 
+// recordLine writes a record (or group) header line, using the top line
+// style for the first line when a border is set.
+func (enc *ExpandedEncoder) recordLine(header string, first bool) {
+	headerRS := enc.rowStyle(enc.lineStyle.Row)
+	if enc.border != 0 {
+		headerRS = enc.rowStyle(enc.lineStyle.Top)
+		if !first {
+			headerRS = enc.rowStyle(enc.lineStyle.Mid)
+		}
+	}
+	_, _ = enc.w.Write(headerRS.left)
+	_, _ = enc.w.WriteString(header)
+	padding := enc.maxWidths[0] + enc.maxWidths[1] + runewidth.StringWidth(string(headerRS.middle))*2 - runewidth.StringWidth(header) - 1
+	if padding > 0 {
+		_, _ = enc.w.Write(bytes.Repeat(headerRS.filler, padding))
+	}
+	// write newline wrap value
+	_, _ = enc.w.Write(headerRS.filler)
+	_, _ = enc.w.Write(headerRS.right)
+}
+
 func (enc *ExpandedEncoder) recordHeader(i int) string {
 	header := fmt.Sprintf("* Record %d", i+1)
 	if enc.border != 0 {
@@ -761,6 +1448,34 @@ func (enc *ExpandedEncoder) recordHeader(i int) string {
 	return header
 }
 
+// groupHeader returns the record header for record i, the first row of a
+// group, including the group key. Only the group key is included when record
+// headers are skipped.
+func (enc *ExpandedEncoder) groupHeader(i int, vals []*Value) string {
+	var key string
+	if v := vals[enc.group.index]; v != nil {
+		key = v.String()
+	}
+	group := fmt.Sprintf("%s: %s", enc.headers[enc.group.index], key)
+	switch {
+	case enc.skipHeader && enc.border != 0:
+		return fmt.Sprintf("[ %s ]", group)
+	case enc.skipHeader:
+		return "* " + group
+	case enc.border != 0:
+		return fmt.Sprintf("[ RECORD %d, %s ]", i+1, group)
+	}
+	return fmt.Sprintf("* Record %d, %s", i+1, group)
+}
+
+// subtotalHeader returns the record header for a group subtotal.
+func (enc *ExpandedEncoder) subtotalHeader() string {
+	if enc.border != 0 {
+		return "[ SUBTOTAL ]"
+	}
+	return "* Subtotal"
+}
+
 // JSONEncoder is an unbuffered JSON encoder for result sets.
 type JSONEncoder struct {
 	resultSet ResultSet
@@ -818,6 +1533,7 @@ func (enc *JSONEncoder) Encode(w io.Writer) error {
 	case clen == 0:
 		return ErrResultSetHasNoColumns
 	}
+	enc.formatter = formatterFor(enc.formatter, enc.resultSet, cols)
 	cb := make([][]byte, clen)
 	for i := range clen {
 		if cb[i], err = json.Marshal(cols[i]); err != nil {
@@ -1010,6 +1726,7 @@ func (enc *UnalignedEncoder) Encode(w io.Writer) error {
 	case clen == 0:
 		return ErrResultSetHasNoColumns
 	}
+	enc.formatter = formatterFor(enc.formatter, enc.resultSet, cols)
 	sep, quote := []byte(string(enc.sep)), []byte(string(enc.quote))
 	// write header
 	if !enc.skipHeader {
@@ -1113,6 +1830,10 @@ type TemplateEncoder struct {
 	headerTransformer Transformer
 	// columnTypes is used to build column types for a result set.
 	columnTypes func(ResultSet, []any, int) error
+	// repeated are the columns to suppress repeated values in.
+	repeated []string
+	// headerGroups are the header groups.
+	headerGroups []HeaderGroup
 }
 
 // NewTemplateEncoder creates a new template encoder using the provided options.
@@ -1146,6 +1867,12 @@ func NewAsciiDocEncoder(resultSet ResultSet, opts ...Option) (Encoder, error) {
 	return NewTemplateEncoder(resultSet, append([]Option{WithTemplate("asciidoc")}, opts...)...)
 }
 
+// NewLaTeXEncoder creates a new LaTeX template encoder using the provided
+// options.
+func NewLaTeXEncoder(resultSet ResultSet, opts ...Option) (Encoder, error) {
+	return NewTemplateEncoder(resultSet, append([]Option{WithTemplate("latex")}, opts...)...)
+}
+
 // NewVerticalEncoder creates a new vertical template encoder using the
 // provided options.
 func NewVerticalEncoder(resultSet ResultSet, opts ...Option) (Encoder, error) {
@@ -1166,6 +1893,7 @@ func (enc *TemplateEncoder) Encode(w io.Writer) error {
 	case clen == 0:
 		return ErrResultSetHasNoColumns
 	}
+	enc.formatter = formatterFor(enc.formatter, enc.resultSet, cols)
 	headers, err := enc.formatter.Header(cols)
 	if err != nil {
 		return err
@@ -1175,6 +1903,16 @@ func (enc *TemplateEncoder) Encode(w io.Writer) error {
 			headers[i] = enc.empty
 		}
 	}
+	repeat, err := newRepeater(cols, enc.repeated, &Value{
+		Buf: []byte(""),
+	})
+	if err != nil {
+		return err
+	}
+	spans, err := buildHeaderSpans(enc.formatter, enc.headerGroups, clen)
+	if err != nil {
+		return err
+	}
 	// set up storage for results
 	r, err := buildColumnTypes(enc.resultSet, clen, enc.columnTypes)
 	if err != nil {
@@ -1188,6 +1926,7 @@ func (enc *TemplateEncoder) Encode(w io.Writer) error {
 		if err != nil {
 			return err
 		}
+		repeat.suppress(vals)
 		for i := range clen {
 			if vals[i] == nil {
 				vals[i] = enc.empty
@@ -1205,7 +1944,9 @@ func (enc *TemplateEncoder) Encode(w io.Writer) error {
 	return enc.executor(w, &Template{
 		Attributes: enc.attributes,
 		Headers:    headers,
+		Spans:      spans,
 		Rows:       rows,
+		RowSpans:   repeat.spans(rows),
 		SkipHeader: enc.skipHeader,
 		Title:      title,
 	})
@@ -1280,6 +2021,53 @@ func buildColNames(resultSet ResultSet, transformer Transformer) (int, []string,
 	return clen, cols, nil
 }
 
+// formatterFor returns the formatter for the column names and types of the
+// result set, when the formatter is a [ColumnTypesFormatter]. Otherwise,
+// returns the formatter.
+func formatterFor(formatter Formatter, resultSet ResultSet, cols []string) Formatter {
+	f, ok := formatter.(ColumnTypesFormatter)
+	if !ok {
+		return formatter
+	}
+	types, err := resultSetColumnTypes(resultSet)
+	if err != nil {
+		types = nil
+	}
+	return f.ForColumnTypes(cols, types)
+}
+
+// buildHeaderSpans builds the formatted header spans for the header groups.
+// Columns not in a named group are returned as single column spans with a nil
+// value.
+func buildHeaderSpans(formatter Formatter, groups []HeaderGroup, n int) ([]HeaderSpan, error) {
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	var spans []HeaderSpan
+	var i int
+	for _, g := range groups {
+		if g.Span < 1 || i+g.Span > n {
+			return nil, ErrInvalidHeaderGroups
+		}
+		i += g.Span
+		if g.Name == "" {
+			for range g.Span {
+				spans = append(spans, HeaderSpan{Span: 1})
+			}
+			continue
+		}
+		v, err := formatter.Header([]string{g.Name})
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, HeaderSpan{Value: v[0], Span: g.Span})
+	}
+	for ; i < n; i++ {
+		spans = append(spans, HeaderSpan{Span: 1})
+	}
+	return spans, nil
+}
+
 // buildColumnTypes builds a []interface{} for storing scan results.
 func buildColumnTypes(resultSet ResultSet, n int, columnTypes func(ResultSet, []any, int) error) ([]any, error) {
 	r := make([]any, n)
@@ -1319,6 +2107,30 @@ func summarize(w io.Writer, summary Summary, count int) error {
 	return nil
 }
 
+// moreRows returns the number of rows remaining after a limit, for result
+// sets wrapping a [LimitView].
+func moreRows(resultSet ResultSet) int {
+	if rs, ok := resultSet.(interface{ More() int }); ok {
+		return rs.More()
+	}
+	return 0
+}
+
+// notifyMore writes the "... N more rows" notice for the rows remaining after
+// a limit, or the "... more rows" notice when the rows were not counted.
+func notifyMore(w io.Writer, resultSet ResultSet, newline []byte) error {
+	var err error
+	switch n := moreRows(resultSet); {
+	case n < 0:
+		_, err = fmt.Fprintf(w, "... more rows%s", newline)
+	case n == 1:
+		_, err = fmt.Fprintf(w, "... %d more row%s", n, newline)
+	case n > 1:
+		_, err = fmt.Fprintf(w, "... %d more rows%s", n, newline)
+	}
+	return err
+}
+
 func startPager(pagerCmd string, w io.Writer) (*exec.Cmd, io.WriteCloser, error) {
 	cmd := exec.Command(pagerCmd)
 	cmd.Stdout = w
@@ -1337,3 +2149,19 @@ func checkErr(err error, cmd *exec.Cmd) error {
 	}
 	return err
 }
+
+// unwrapValue dereferences v and returns the underlying value of a
+// driver.Valuer. Returns nil when the value is NULL or cannot be retrieved.
+func unwrapValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	v = deref(v)
+	if z, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = z.Value(); err != nil {
+			return nil
+		}
+	}
+	return v
+}
@@ -3,8 +3,13 @@ package tblfmt
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
@@ -26,6 +31,17 @@ type Formatter interface {
 	Format([]any) ([]*Value, error)
 }
 
+// ColumnTypesFormatter is the interface for formatters formatting values
+// using the column types of the result set.
+type ColumnTypesFormatter interface {
+	Formatter
+	// ForColumnTypes returns a formatter for the column names and types of the
+	// result set being formatted, leaving the formatter unchanged, as it may be
+	// shared by multiple encoders. The types are nil when the result set does
+	// not provide column types.
+	ForColumnTypes([]string, []*sql.ColumnType) Formatter
+}
+
 // EscapeFormatter is an escaping formatter, that handles formatting the
 // standard Go types.
 //
@@ -40,6 +56,29 @@ type EscapeFormatter struct {
 	mask string
 	// timeFormat is the format to use for time values.
 	timeFormat string
+	// dateFormat, timeOfDayFormat, timestampFormat, and timestamptzFormat are
+	// the formats to use for time values of date, time, timestamp, and
+	// timestamp with time zone columns.
+	dateFormat, timeOfDayFormat, timestampFormat, timestamptzFormat string
+	// durationFormat is the format to use for duration values.
+	durationFormat DurationFormat
+	// jsonPretty toggles re-indenting JSON text values.
+	jsonPretty bool
+	// jsonMaxDepth is the maximum depth of re-indented JSON text values.
+	jsonMaxDepth int
+	// jsonHighlight toggles highlighting re-indented JSON text values.
+	jsonHighlight bool
+	// null is the text used for NULL values in composite values (ie, changed
+	// diff values).
+	null string
+	// diffHighlight toggles highlighting changed diff values.
+	diffHighlight bool
+	// binaryFormat is the binary format of all columns.
+	binaryFormat BinaryFormat
+	// columnBinaryFormats are the binary formats of specific columns.
+	columnBinaryFormats map[string]BinaryFormat
+	// binaryPreview is the number of bytes of a binary preview.
+	binaryPreview int
 	// timeLocation is the location to use for time values.
 	timeLocation *time.Location
 	// encoder will be used to encode map[string]interface{} and []interface{}
@@ -72,19 +111,55 @@ type EscapeFormatter struct {
 	align Align
 	// numericLocalePrinter is the numeric locale printer.
 	numericLocalePrinter *message.Printer
+	// numericGroup and numericPoint are the group and decimal separators of
+	// the numeric locale.
+	numericGroup, numericPoint string
+	// scale is the number of decimal digits arbitrary precision numbers are
+	// rounded to. Not rounded when less than 0.
+	scale int
+	// rounding is the rounding mode used with scale.
+	rounding big.RoundingMode
+	// numberFormat is the number format of all columns.
+	numberFormat numberFormat
+	// columnNumberFormats are the number formats of specific columns.
+	columnNumberFormats map[string]*numberFormat
+	// cols are the column names.
+	cols []string
+	// dbTypes are the database type names of the columns.
+	dbTypes []string
+	// typeFormatters are the registered type formatters.
+	typeFormatters map[reflect.Type]TypeFormatterFunc
+	// interfaceFormatters are the registered interface formatters, in
+	// registration order.
+	interfaceFormatters []interfaceFormatter
+}
+
+// interfaceFormatter is a registered interface formatter.
+type interfaceFormatter struct {
+	typ reflect.Type
+	f   TypeFormatterFunc
 }
 
 // NewEscapeFormatter creates a escape formatter to handle basic Go values,
-// such as []byte, string, time.Time, and sql.Null*. Formatting for
+// such as []byte, string, time.Time, sql.Null*, math/big numbers, and
+// driver.Valuer (formatted using its underlying value). Formatting for
 // map[string]interface{} and []interface{} will be passed to a marshaler
 // provided by [WithEncoder], otherwise the standard [encoding/json.Encoder]
 // will be used to marshal those values.
 func NewEscapeFormatter(opts ...EscapeFormatterOption) *EscapeFormatter {
 	f := &EscapeFormatter{
-		mask:       "%d",
-		timeFormat: time.RFC3339Nano,
-		indent:     "  ",
-		align:      -1,
+		mask:            "%d",
+		timeFormat:      time.RFC3339Nano,
+		dateFormat:      "2006-01-02",
+		timeOfDayFormat: "15:04:05.999999999",
+		indent:          "  ",
+		align:           -1,
+		scale:           -1,
+		binaryPreview:   16,
+		null:            "NULL",
+		numberFormat: numberFormat{
+			decimals: -1,
+		},
 	}
 	for _, o := range opts {
 		o(f)
@@ -110,169 +185,1102 @@ func (f *EscapeFormatter) Header(headers []string) ([]*Value, error) {
 	return res, nil
 }
 
+// ForColumnTypes satisfies the ColumnTypesFormatter interface, returning a
+// copy of the formatter for the column names and types.
+func (f *EscapeFormatter) ForColumnTypes(cols []string, types []*sql.ColumnType) Formatter {
+	z := *f
+	z.cols, z.dbTypes = cols, make([]string, len(types))
+	for i, typ := range types {
+		z.dbTypes[i] = strings.ToUpper(typ.DatabaseTypeName())
+	}
+	return &z
+}
+
+// dbType returns the database type name of column i, without any type
+// parameters (ie, "NUMERIC" for "NUMERIC(10,2)").
+func (f *EscapeFormatter) dbType(i int) string {
+	if i >= len(f.dbTypes) {
+		return ""
+	}
+	typ, _, _ := strings.Cut(f.dbTypes[i], "(")
+	return strings.TrimSpace(typ)
+}
+
 // Format satisfies the Formatter interface.
 func (f *EscapeFormatter) Format(vals []any) ([]*Value, error) {
-	n := len(vals)
-	res := make([]*Value, n)
+	res := make([]*Value, len(vals))
 	// TODO: change time to v.AppendFormat() + pool
 	// TODO: use strconv.Format* for numeric times
 	// TODO: use pool
 	// TODO: allow configurable runes that can be escaped
-	// TODO: handler driver.Valuer
+	for i := range vals {
+		var err error
+		if fn, v := f.pointerFormatter(vals[i]); fn != nil {
+			res[i], err = f.formatType(fn, v)
+		} else {
+			res[i], err = f.format(i, deref(vals[i]))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// format formats the value of column i.
+func (f *EscapeFormatter) format(i int, val any) (*Value, error) {
 	left, right := AlignLeft, AlignRight
 	if f.align != -1 {
 		left, right = f.align, f.align
 	}
-	for i := range n {
-		val := deref(vals[i])
-		switch v := val.(type) {
-		case nil:
-		case bool:
-			res[i] = newValue(strconv.FormatBool(v), left, true)
-		case int, int8, int16, int32, int64,
-			uint, uint8, uint16, uint32, uint64:
+	if fn, v := f.typeFormatter(val); fn != nil {
+		return f.formatType(fn, v)
+	}
+	nf := f.columnNumberFormat(i)
+	if s, ok := decimalText(val); ok && isNumericType(f.dbType(i)) {
+		return f.formatNumeric(s, nf.decimals, right), nil
+	}
+	if x, bitSize, ok := machineNumber(val); ok && nf.active() {
+		return f.formatNumber(x, bitSize, nf, right), nil
+	}
+	if b, ok := binaryBytes(val); ok {
+		if bf := f.columnBinaryFormat(i); bf != BinaryDefault && f.isBinary(i, b) {
+			return f.formatBinary(b, bf, left), nil
+		}
+	}
+	if f.jsonPretty && !f.isJSON && !f.isRaw {
+		if b, ok := jsonText(val); ok {
+			return f.formatJSONText(b, left), nil
+		}
+	}
+	var res *Value
+	switch v := val.(type) {
+	case nil:
+	case Aligned:
+		z, err := f.format(i, v.Value)
+		if err != nil {
+			return nil, err
+		}
+		if res = z; res != nil && f.align == -1 {
+			res.Align = v.Align
+		}
+	case bool:
+		res = newValue(strconv.FormatBool(v), left, true)
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		var s string
+		if f.numericLocalePrinter != nil {
+			s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v))
+		} else {
+			s = fmt.Sprintf("%d", v)
+		}
+		res = f.numericValue(s, right)
+	case float32:
+		var s string
+		if f.numericLocalePrinter != nil {
+			s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v, number.MinFractionDigits(1)))
+		} else {
+			s = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		}
+		res = f.numericValue(s, right)
+	case float64:
+		var s string
+		if f.numericLocalePrinter != nil {
+			s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v, number.MinFractionDigits(1)))
+		} else {
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		res = f.numericValue(s, right)
+	case uintptr:
+		res = newValue(fmt.Sprintf("(0x%x)", v), right, true)
+	case complex64:
+		res = newValue(fmt.Sprintf("%g", v), right, false)
+	case complex128:
+		res = newValue(fmt.Sprintf("%g", v), right, false)
+	case big.Int, big.Float, big.Rat, *big.Int, *big.Float, *big.Rat:
+		res = f.formatNumeric(v, nf.decimals, right)
+	case []byte:
+		res = FormatBytes(v, f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+	case string:
+		res = FormatBytes([]byte(v), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+	case time.Time:
+		res = f.formatTime(i, v, left)
+	case time.Duration:
+		res = f.formatDuration(v, right)
+	case sql.NullBool:
+		if v.Valid {
+			res = newValue(strconv.FormatBool(v.Bool), left, true)
+		}
+	case sql.NullByte:
+		if v.Valid {
 			var s string
 			if f.numericLocalePrinter != nil {
-				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v))
+				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Byte))
 			} else {
-				s = fmt.Sprintf("%d", v)
+				s = strconv.FormatUint(uint64(v.Byte), 10)
 			}
-			res[i] = newValue(s, right, true)
-		case float32:
+			res = f.numericValue(s, right)
+		}
+	case sql.NullFloat64:
+		if v.Valid {
 			var s string
 			if f.numericLocalePrinter != nil {
-				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v, number.MinFractionDigits(1)))
+				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Float64))
 			} else {
-				s = strconv.FormatFloat(float64(v), 'g', -1, 32)
+				s = strconv.FormatFloat(v.Float64, 'g', -1, 64)
 			}
-			res[i] = newValue(s, right, true)
-		case float64:
+			res = f.numericValue(s, right)
+		}
+	case sql.NullInt16:
+		if v.Valid {
 			var s string
 			if f.numericLocalePrinter != nil {
-				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v, number.MinFractionDigits(1)))
+				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int16))
 			} else {
-				s = strconv.FormatFloat(v, 'g', -1, 64)
+				s = strconv.FormatInt(int64(v.Int16), 10)
 			}
-			res[i] = newValue(s, right, true)
-		case uintptr:
-			res[i] = newValue(fmt.Sprintf("(0x%x)", v), right, true)
-		case complex64:
-			res[i] = newValue(fmt.Sprintf("%g", v), right, false)
-		case complex128:
-			res[i] = newValue(fmt.Sprintf("%g", v), right, false)
-		case []byte:
-			res[i] = FormatBytes(v, f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
-		case string:
-			res[i] = FormatBytes([]byte(v), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
-		case time.Time:
-			t := v
-			if f.timeLocation != nil {
-				t = t.In(f.timeLocation)
+			res = f.numericValue(s, right)
+		}
+	case sql.NullInt32:
+		if v.Valid {
+			var s string
+			if f.numericLocalePrinter != nil {
+				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int32))
+			} else {
+				s = strconv.FormatInt(int64(v.Int32), 10)
 			}
-			res[i] = newValue(t.Format(f.timeFormat), left, false)
-		case sql.NullBool:
-			if v.Valid {
-				res[i] = newValue(strconv.FormatBool(v.Bool), left, true)
+			res = f.numericValue(s, right)
+		}
+	case sql.NullInt64:
+		if v.Valid {
+			var s string
+			if f.numericLocalePrinter != nil {
+				s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int64))
+			} else {
+				s = strconv.FormatInt(v.Int64, 10)
 			}
-		case sql.NullByte:
-			if v.Valid {
-				var s string
-				if f.numericLocalePrinter != nil {
-					s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Byte))
-				} else {
-					s = strconv.FormatUint(uint64(v.Byte), 10)
-				}
-				res[i] = newValue(s, right, true)
+			res = f.numericValue(s, right)
+		}
+	case sql.NullString:
+		if v.Valid {
+			res = FormatBytes([]byte(v.String), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+		}
+	case sql.NullTime:
+		if v.Valid {
+			res = f.formatTime(i, v.Time, left)
+		}
+	case sql.RawBytes:
+		res = FormatBytes(v, f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+	case driver.Valuer:
+		var err error
+		if res, err = f.formatValuer(i, v, right); err != nil {
+			return nil, err
+		}
+	case DiffValue:
+		var err error
+		if res, err = f.formatDiff(i, v, left); err != nil {
+			return nil, err
+		}
+	case fmt.Stringer:
+		res = FormatBytes([]byte(v.String()), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+	default:
+		// TODO: pool
+		if f.encoder != nil {
+			buf, err := f.encoder(v)
+			if err != nil {
+				return nil, err
 			}
-		case sql.NullFloat64:
-			if v.Valid {
-				var s string
-				if f.numericLocalePrinter != nil {
-					s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Float64))
-				} else {
-					s = strconv.FormatFloat(v.Float64, 'g', -1, 64)
-				}
-				res[i] = newValue(s, right, true)
+			res = &Value{
+				Buf: buf,
+				Raw: true,
 			}
-		case sql.NullInt16:
-			if v.Valid {
-				var s string
-				if f.numericLocalePrinter != nil {
-					s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int16))
-				} else {
-					s = strconv.FormatInt(int64(v.Int16), 10)
-				}
-				res[i] = newValue(s, right, true)
+		} else {
+			// json encode
+			buf := new(bytes.Buffer)
+			enc := json.NewEncoder(buf)
+			enc.SetIndent(f.prefix, f.indent)
+			enc.SetEscapeHTML(f.escapeHTML)
+			if err := enc.Encode(v); err != nil {
+				return nil, err
 			}
-		case sql.NullInt32:
-			if v.Valid {
-				var s string
-				if f.numericLocalePrinter != nil {
-					s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int32))
-				} else {
-					s = strconv.FormatInt(int64(v.Int32), 10)
+			if f.isJSON {
+				res = &Value{
+					Buf: bytes.TrimSpace(buf.Bytes()),
+					Raw: true,
 				}
-				res[i] = newValue(s, right, true)
+			} else {
+				res = FormatBytes(bytes.TrimSpace(buf.Bytes()), f.invalid, f.invalidWidth, false, f.isRaw, f.sep, f.quote)
+				res.Raw = true
 			}
-		case sql.NullInt64:
-			if v.Valid {
-				var s string
-				if f.numericLocalePrinter != nil {
-					s = f.numericLocalePrinter.Sprintf("%v", number.Decimal(v.Int64))
-				} else {
-					s = strconv.FormatInt(v.Int64, 10)
-				}
-				res[i] = newValue(s, right, true)
+		}
+	}
+	return res, nil
+}
+
+// typeFormatter returns the registered type formatter for v, if any, and the
+// value to pass to the formatter.
+func (f *EscapeFormatter) typeFormatter(v any) (TypeFormatterFunc, any) {
+	if v == nil || f.typeFormatters == nil && f.interfaceFormatters == nil {
+		return nil, nil
+	}
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Pointer && val.IsNil() {
+		return nil, nil
+	}
+	typ := val.Type()
+	if fn, ok := f.typeFormatters[typ]; ok {
+		return fn, v
+	}
+	for _, z := range f.interfaceFormatters {
+		switch {
+		case typ.Implements(z.typ):
+			return z.f, v
+		case typ.Kind() != reflect.Pointer && reflect.PointerTo(typ).Implements(z.typ):
+			// methods with pointer receivers
+			p := reflect.New(typ)
+			p.Elem().Set(val)
+			return z.f, p.Interface()
+		}
+	}
+	return nil, nil
+}
+
+// pointerFormatter returns the type formatter registered for the pointer type
+// of a scanned value (ie, *big.Int), before the value is dereferenced, and the
+// pointer.
+func (f *EscapeFormatter) pointerFormatter(v any) (TypeFormatterFunc, any) {
+	if z, ok := v.(*any); ok && z != nil {
+		v = *z
+	}
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		return nil, nil
+	}
+	fn, ok := f.typeFormatters[val.Type()]
+	if !ok {
+		return nil, nil
+	}
+	return fn, v
+}
+
+// formatType formats v using a registered type formatter.
+func (f *EscapeFormatter) formatType(fn TypeFormatterFunc, v any) (*Value, error) {
+	z, err := fn(v)
+	if err != nil {
+		return nil, err
+	}
+	align := z.Align
+	if f.align != -1 {
+		align = f.align
+	}
+	var res *Value
+	if z.Raw {
+		res = newValue(z.Text, align, true)
+	} else {
+		res = FormatBytes([]byte(z.Text), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+		res.Align = align
+	}
+	res.Quoted = res.Quoted || z.Quoted
+	return res, nil
+}
+
+// formatTime formats a time value of column i, using the format for the
+// column's database type. The time location is not used for the values of
+// date, time, and timestamp (without time zone) columns.
+func (f *EscapeFormatter) formatTime(i int, t time.Time, align Align) *Value {
+	layout, local := f.timeFormat, true
+	switch f.dbType(i) {
+	case "DATE":
+		layout, local = f.dateFormat, false
+	case "TIME", "TIMETZ", "TIME WITH TIME ZONE", "TIME WITHOUT TIME ZONE":
+		layout, local = f.timeOfDayFormat, false
+	case "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE", "DATETIME", "DATETIME2", "SMALLDATETIME":
+		layout, local = f.timestampFormat, false
+	case "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE", "DATETIMEOFFSET":
+		layout = f.timestamptzFormat
+	}
+	if layout == "" {
+		layout = f.timeFormat
+	}
+	if local && f.timeLocation != nil {
+		t = t.In(f.timeLocation)
+	}
+	if layout == PostgresTimestamptzFormat {
+		return newValue(postgresTimestamptz(t), align, false)
+	}
+	return newValue(t.Format(layout), align, false)
+}
+
+// postgresTimestamptz formats a time the same as a Postgres timestamp with
+// time zone. As Go's "-07" layout drops the minutes of the offset, the offset
+// is formatted as hours, followed by minutes and seconds only when not zero
+// (ie, "+05:30" for Asia/Kolkata).
+func postgresTimestamptz(t time.Time) string {
+	_, off := t.Zone()
+	sign := '+'
+	if off < 0 {
+		sign, off = '-', -off
+	}
+	s := t.Format(PostgresTimestampFormat) + fmt.Sprintf("%c%02d", sign, off/3600)
+	switch mins, secs := off%3600/60, off%60; {
+	case secs != 0:
+		s += fmt.Sprintf(":%02d:%02d", mins, secs)
+	case mins != 0:
+		s += fmt.Sprintf(":%02d", mins)
+	}
+	return s
+}
+
+// formatDuration formats a duration value.
+func (f *EscapeFormatter) formatDuration(d time.Duration, align Align) *Value {
+	switch f.durationFormat {
+	case DurationPostgres:
+		return newValue(postgresDuration(d), align, false)
+	case DurationSeconds:
+		return f.numericValue(strconv.FormatFloat(d.Seconds(), 'f', -1, 64), align)
+	}
+	return newValue(d.String(), align, false)
+}
+
+// postgresDuration formats a duration the same as a Postgres interval (ie, "1
+// day 02:03:04.5").
+func postgresDuration(d time.Duration) string {
+	var sign string
+	u := uint64(d)
+	if d < 0 {
+		sign, u = "-", -u
+	}
+	day := uint64(24 * time.Hour)
+	days, u := u/day, u%day
+	h, u := u/uint64(time.Hour), u%uint64(time.Hour)
+	m, u := u/uint64(time.Minute), u%uint64(time.Minute)
+	s, ns := u/uint64(time.Second), u%uint64(time.Second)
+	clock := fmt.Sprintf("%s%02d:%02d:%02d", sign, h, m, s)
+	if ns != 0 {
+		clock += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+	}
+	switch {
+	case days == 0:
+		return clock
+	case days == 1 && sign == "":
+		return "1 day " + clock
+	}
+	return fmt.Sprintf("%s%d days %s", sign, days, clock)
+}
+
+// columnBinaryFormat returns the binary format of column i.
+func (f *EscapeFormatter) columnBinaryFormat(i int) BinaryFormat {
+	if i < len(f.cols) {
+		if bf, ok := f.columnBinaryFormats[f.cols[i]]; ok {
+			return bf
+		}
+	}
+	return f.binaryFormat
+}
+
+// isBinary returns true when the bytes of column i are binary data. Bytes of
+// columns with a binary database type are always binary, while bytes of
+// columns with any other known database type are never binary. Otherwise,
+// bytes are binary when not valid UTF-8 text.
+func (f *EscapeFormatter) isBinary(i int, b []byte) bool {
+	switch typ := f.dbType(i); typ {
+	case "BYTEA", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY",
+		"BINARY VARYING", "IMAGE", "RAW", "LONG RAW":
+		return true
+	case "":
+	default:
+		return false
+	}
+	if !utf8.Valid(b) {
+		return true
+	}
+	for _, c := range b {
+		if c < ' ' && c != '\t' && c != '\n' && c != '\r' || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBinary formats binary data using the binary format.
+func (f *EscapeFormatter) formatBinary(b []byte, bf BinaryFormat, align Align) *Value {
+	var s string
+	switch bf {
+	case BinaryHex:
+		s = `\x` + hex.EncodeToString(b)
+	case BinaryBase64:
+		s = base64.StdEncoding.EncodeToString(b)
+	case BinaryEscape:
+		s = byteaEscape(b)
+	case BinaryPreview:
+		if len(b) <= f.binaryPreview {
+			s = `\x` + hex.EncodeToString(b)
+		} else {
+			s = `\x` + hex.EncodeToString(b[:f.binaryPreview]) + "... (" + strconv.Itoa(len(b)) + " bytes)"
+		}
+	case BinaryHexdump:
+		s = strings.TrimSuffix(hex.Dump(b), "\n")
+	}
+	v := FormatBytes([]byte(s), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+	v.Align = align
+	return v
+}
+
+// byteaEscape encodes b using the Postgres bytea escape format, where
+// printable ASCII characters other than backslash are unchanged, and all other
+// bytes are encoded as a backslash and 3 octal digits.
+func byteaEscape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case ' ' <= c && c < 0x7f:
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte('0' + c>>6)
+			sb.WriteByte('0' + c>>3&7)
+			sb.WriteByte('0' + c&7)
+		}
+	}
+	return sb.String()
+}
+
+// binaryBytes returns the bytes of v, when v is a []byte or sql.RawBytes.
+func binaryBytes(v any) ([]byte, bool) {
+	switch z := v.(type) {
+	case []byte:
+		return z, true
+	case sql.RawBytes:
+		return z, true
+	}
+	return nil, false
+}
+
+// JSON highlight colors.
+const (
+	jsonKeyColor    = "\x1b[34;1m"
+	jsonStringColor = "\x1b[32m"
+	jsonNumberColor = "\x1b[36m"
+)
+
+// Diff highlight colors.
+const (
+	diffOldColor = "\x1b[31m"
+	diffNewColor = "\x1b[32m"
+)
+
+// resetColor resets the color.
+const resetColor = "\x1b[0m"
+
+// jsonText returns the text of a string or bytes value, when it is a JSON
+// object or array.
+func jsonText(v any) ([]byte, bool) {
+	var b []byte
+	switch z := v.(type) {
+	case string:
+		b = []byte(z)
+	case []byte:
+		b = z
+	case sql.RawBytes:
+		b = z
+	case json.RawMessage:
+		b = z
+	case sql.NullString:
+		b = []byte(z.String)
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || b[0] != '{' && b[0] != '[' || !json.Valid(b) {
+		return nil, false
+	}
+	return b, true
+}
+
+// formatJSONText re-indents valid JSON text, writing nesting deeper than the
+// max depth on a single line, and highlighting keys, strings, and numbers.
+// Highlight escape sequences are not included in the value's width.
+func (f *EscapeFormatter) formatJSONText(src []byte, align Align) *Value {
+	v := &Value{
+		Tabs:  make([][][2]int, 1),
+		Align: align,
+	}
+	expanded := func(depth int) bool {
+		return f.jsonMaxDepth <= 0 || depth <= f.jsonMaxDepth
+	}
+	newline := func(depth int) {
+		appendText(v, "\n"+f.prefix+strings.Repeat(f.indent, depth))
+	}
+	// next returns the index of the next non-space byte after i
+	next := func(i int) int {
+		for i++; i < len(src) && isJSONSpace(src[i]); i++ {
+		}
+		return i
+	}
+	var depth int
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case ' ', '\t', '\n', '\r':
+		case '{', '[':
+			if j := next(i); j < len(src) && (src[j] == '}' || src[j] == ']') {
+				appendText(v, string(c)+string(src[j]))
+				i = j
+				continue
+			}
+			appendText(v, string(c))
+			if depth++; expanded(depth) {
+				newline(depth)
+			}
+		case '}', ']':
+			if expanded(depth) {
+				newline(depth - 1)
+			}
+			depth--
+			appendText(v, string(c))
+		case ',':
+			appendText(v, ",")
+			if expanded(depth) {
+				newline(depth)
 			}
-		case sql.NullString:
-			if v.Valid {
-				res[i] = FormatBytes([]byte(v.String), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+		case ':':
+			appendText(v, ":")
+			if expanded(depth) {
+				appendText(v, " ")
 			}
-		case sql.NullTime:
-			if v.Valid {
-				t := v.Time
-				if f.timeLocation != nil {
-					t = t.In(f.timeLocation)
+		case '"':
+			j := i + 1
+			for ; src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
 				}
-				res[i] = newValue(t.Format(f.timeFormat), left, false)
 			}
-		case sql.RawBytes:
-			res[i] = FormatBytes(v, f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
-		case fmt.Stringer:
-			res[i] = FormatBytes([]byte(v.String()), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote)
+			color := jsonStringColor
+			if k := next(j); k < len(src) && src[k] == ':' {
+				color = jsonKeyColor
+			}
+			f.appendJSONToken(v, src[i:j+1], color)
+			i = j
 		default:
-			// TODO: pool
-			if f.encoder != nil {
-				buf, err := f.encoder(v)
-				if err != nil {
-					return nil, err
-				}
-				res[i] = &Value{
-					Buf: buf,
-					Raw: true,
-				}
-			} else {
-				// json encode
-				buf := new(bytes.Buffer)
-				enc := json.NewEncoder(buf)
-				enc.SetIndent(f.prefix, f.indent)
-				enc.SetEscapeHTML(f.escapeHTML)
-				if err := enc.Encode(v); err != nil {
-					return nil, err
-				}
-				if f.isJSON {
-					res[i] = &Value{
-						Buf: bytes.TrimSpace(buf.Bytes()),
-						Raw: true,
-					}
-				} else {
-					res[i] = FormatBytes(bytes.TrimSpace(buf.Bytes()), f.invalid, f.invalidWidth, false, f.isRaw, f.sep, f.quote)
-					res[i].Raw = true
-				}
+			j := i
+			for ; j < len(src) && !isJSONSpace(src[j]) && !strings.ContainsRune(",:]}", rune(src[j])); j++ {
+			}
+			var color string
+			if c == '-' || '0' <= c && c <= '9' {
+				color = jsonNumberColor
 			}
+			f.appendJSONToken(v, src[i:j], color)
+			i = j - 1
 		}
 	}
-	return res, nil
+	return v
+}
+
+// appendJSONToken appends a JSON token to the value, escaping the same as
+// other values, and highlighting the token with the color.
+func (f *EscapeFormatter) appendJSONToken(v *Value, token []byte, color string) {
+	z := FormatBytes(token, f.invalid, f.invalidWidth, false, false, 0, 0)
+	if f.jsonHighlight && color != "" {
+		v.Buf = append(v.Buf, color...)
+		v.Buf = append(v.Buf, z.Buf...)
+		v.Buf = append(v.Buf, resetColor...)
+	} else {
+		v.Buf = append(v.Buf, z.Buf...)
+	}
+	v.Width += z.Width
+}
+
+// formatDiff formats a changed diff value of column i as its old and new
+// values separated by an arrow, with NULL values formatted as the null text.
+// The old and new values are highlighted when enabled, with the color escape
+// sequences not included in the value's width.
+func (f *EscapeFormatter) formatDiff(i int, d DiffValue, align Align) (*Value, error) {
+	v := &Value{
+		Tabs:   make([][][2]int, 1),
+		Align:  align,
+		Quoted: f.isRaw,
+	}
+	highlight := f.diffHighlight && !f.isJSON && !f.isRaw
+	for j, z := range []any{d.Old, d.New} {
+		if j != 0 {
+			appendText(v, " → ")
+		}
+		s := f.null
+		if z != nil {
+			x, err := f.format(i, z)
+			if err != nil {
+				return nil, err
+			}
+			if x != nil {
+				s = string(x.Buf)
+			}
+		}
+		if !highlight {
+			appendText(v, s)
+			continue
+		}
+		color := diffOldColor
+		if j != 0 {
+			color = diffNewColor
+		}
+		// highlight each line, to not color table borders
+		for k, line := range strings.Split(s, "\n") {
+			if k != 0 {
+				appendText(v, "\n")
+			}
+			if line != "" {
+				v.Buf = append(v.Buf, color...)
+				appendText(v, line)
+				v.Buf = append(v.Buf, resetColor...)
+			}
+		}
+	}
+	return v, nil
+}
+
+// appendText appends text to the value, tracking the positions of newlines
+// and tabs, and the width.
+func appendText(v *Value, s string) {
+	for _, r := range s {
+		switch r {
+		case '\n':
+			v.Newlines = append(v.Newlines, [2]int{len(v.Buf), v.Width})
+			v.Buf = append(v.Buf, '\n')
+			v.Width = 0
+			v.Tabs = append(v.Tabs, nil)
+		case '\t':
+			v.Tabs[len(v.Tabs)-1] = append(v.Tabs[len(v.Tabs)-1], [2]int{len(v.Buf), v.Width})
+			v.Buf = append(v.Buf, '\t')
+			v.Width = 0
+		default:
+			v.Buf = utf8.AppendRune(v.Buf, r)
+			v.Width += runewidth.RuneWidth(r)
+		}
+	}
+}
+
+// isJSONSpace returns true when c is JSON whitespace.
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// formatValuer formats the underlying value of a driver.Valuer, or of a
+// generic sql.Null[T]. Decimal strings (ie, as returned by arbitrary precision
+// decimal types) are formatted as numbers.
+func (f *EscapeFormatter) formatValuer(i int, v driver.Valuer, right Align) (*Value, error) {
+	var z any
+	if val := reflect.ValueOf(v); isSQLNull(val.Type()) {
+		if !val.FieldByName("Valid").Bool() {
+			return nil, nil
+		}
+		z = val.FieldByName("V").Interface()
+	} else {
+		var err error
+		if z, err = v.Value(); err != nil {
+			return nil, err
+		}
+	}
+	switch s := z.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if isDecimal(s) && (isNumericType(f.dbType(i)) || isDecimalType(reflect.TypeOf(v))) {
+			return f.formatNumeric(s, f.columnNumberFormat(i).decimals, right), nil
+		}
+	case driver.Valuer:
+		// avoid recursing on values returning themselves
+		if reflect.TypeOf(s) == reflect.TypeOf(v) {
+			return FormatBytes([]byte(fmt.Sprint(s)), f.invalid, f.invalidWidth, f.isJSON, f.isRaw, f.sep, f.quote), nil
+		}
+	}
+	return f.format(i, z)
+}
+
+// numberFormat is a number format.
+type numberFormat struct {
+	// decimals is the fixed number of decimal places. Not fixed when less than
+	// 0.
+	decimals int
+	// digits is the number of significant digits. Not used when 0.
+	digits int
+	// notation is the notation.
+	notation Notation
+	// percent toggles formatting as a percentage.
+	percent bool
+	// size is the byte size units.
+	size ByteSize
+}
+
+// active returns true when the number format changes the default formatting.
+func (nf numberFormat) active() bool {
+	return nf != numberFormat{decimals: -1}
+}
+
+// columnNumberFormat returns the number format of column i.
+func (f *EscapeFormatter) columnNumberFormat(i int) numberFormat {
+	nf := f.numberFormat
+	if i >= len(f.cols) {
+		return nf
+	}
+	z, ok := f.columnNumberFormats[f.cols[i]]
+	if !ok {
+		return nf
+	}
+	if z.decimals >= 0 {
+		nf.decimals = z.decimals
+	}
+	if z.digits > 0 {
+		nf.digits = z.digits
+	}
+	if z.notation != NotationDefault {
+		nf.notation = z.notation
+	}
+	if z.percent {
+		nf.percent = true
+	}
+	if z.size != ByteSizeNone {
+		nf.size = z.size
+	}
+	return nf
+}
+
+// Byte size units.
+var (
+	binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siUnits     = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// formatNumber formats a machine number using the number format. Integers
+// have a bit size of 0.
+func (f *EscapeFormatter) formatNumber(x float64, bitSize int, nf numberFormat, align Align) *Value {
+	var suffix string
+	decimals := nf.decimals
+	switch {
+	case nf.size != ByteSizeNone:
+		base, units := 1024.0, binaryUnits
+		if nf.size == ByteSizeSI {
+			base, units = 1000, siUnits
+		}
+		var u int
+		for ; math.Abs(x) >= base && u < len(units)-1; u++ {
+			x /= base
+		}
+		if u != 0 && decimals < 0 && nf.digits == 0 {
+			decimals = 1
+		}
+		suffix = " " + units[u]
+	case nf.percent:
+		x, suffix = x*100, "%"
+	}
+	plain := bitSize == 0 || nf.notation == NotationPlain
+	if bitSize == 0 {
+		bitSize = 64
+	}
+	var s string
+	switch {
+	case nf.notation == NotationScientific:
+		prec := decimals
+		if prec < 0 {
+			prec = nf.digits - 1
+		}
+		s = strconv.FormatFloat(x, 'e', prec, bitSize)
+	case decimals >= 0:
+		s = strconv.FormatFloat(x, 'f', decimals, bitSize)
+	case nf.digits > 0:
+		s = strconv.FormatFloat(x, 'g', nf.digits, bitSize)
+		if plain && strings.Contains(s, "e") {
+			y, _ := strconv.ParseFloat(s, 64)
+			s = strconv.FormatFloat(y, 'f', -1, 64)
+		}
+	case plain:
+		s = strconv.FormatFloat(x, 'f', -1, bitSize)
+	default:
+		s = strconv.FormatFloat(x, 'g', -1, bitSize)
+	}
+	if f.numericLocalePrinter != nil {
+		s = f.localizeDecimal(s)
+	}
+	v := f.numericValue(s+suffix, align)
+	if suffix != "" {
+		v.Raw, v.Numeric = false, nf.size == ByteSizeNone
+	}
+	return v
+}
+
+// machineNumber returns v as a float64, when v is a machine number, along with
+// its bit size (0 for integers).
+func machineNumber(v any) (float64, int, bool) {
+	switch z := v.(type) {
+	case int:
+		return float64(z), 0, true
+	case int8:
+		return float64(z), 0, true
+	case int16:
+		return float64(z), 0, true
+	case int32:
+		return float64(z), 0, true
+	case int64:
+		return float64(z), 0, true
+	case uint:
+		return float64(z), 0, true
+	case uint8:
+		return float64(z), 0, true
+	case uint16:
+		return float64(z), 0, true
+	case uint32:
+		return float64(z), 0, true
+	case uint64:
+		return float64(z), 0, true
+	case float32:
+		return float64(z), 32, true
+	case float64:
+		return z, 64, true
+	case sql.NullByte:
+		return float64(z.Byte), 0, z.Valid
+	case sql.NullInt16:
+		return float64(z.Int16), 0, z.Valid
+	case sql.NullInt32:
+		return float64(z.Int32), 0, z.Valid
+	case sql.NullInt64:
+		return float64(z.Int64), 0, z.Valid
+	case sql.NullFloat64:
+		return z.Float64, 64, z.Valid
+	}
+	return 0, 0, false
+}
+
+// ratScale is the number of decimal digits used for rationals without a
+// finite decimal representation, when no scale has been set.
+const ratScale = 16
+
+// formatNumeric formats an arbitrary precision number (a decimal string, or a
+// big.Int, big.Float, or big.Rat), rounding to the fixed decimal places or the
+// scale, and applying the numeric locale.
+func (f *EscapeFormatter) formatNumeric(z any, decimals int, align Align) *Value {
+	scale := f.scale
+	if decimals >= 0 {
+		scale = decimals
+	}
+	var s string
+	var r *big.Rat
+	switch v := z.(type) {
+	case big.Int:
+		return f.formatNumeric(&v, decimals, align)
+	case big.Float:
+		return f.formatNumeric(&v, decimals, align)
+	case big.Rat:
+		return f.formatNumeric(&v, decimals, align)
+	case string:
+		s = v
+		if scale >= 0 {
+			r, _ = new(big.Rat).SetString(v)
+		}
+	case *big.Int:
+		if v == nil {
+			return nil
+		}
+		s = v.String()
+		if scale >= 0 {
+			r = new(big.Rat).SetInt(v)
+		}
+	case *big.Float:
+		switch {
+		case v == nil:
+			return nil
+		case v.IsInf():
+			return f.numericValue(v.String(), align)
+		}
+		s = v.Text('f', -1)
+		if scale >= 0 {
+			r, _ = v.Rat(nil)
+		}
+	case *big.Rat:
+		if v == nil {
+			return nil
+		}
+		r = v
+		if n, exact := v.FloatPrec(); exact {
+			s = v.FloatString(n)
+		} else {
+			s = v.FloatString(ratScale)
+		}
+	}
+	if r != nil && scale >= 0 {
+		s = roundRat(r, scale, f.rounding)
+	}
+	if f.numericLocalePrinter != nil {
+		s = f.localizeDecimal(s)
+	}
+	return f.numericValue(s, align)
+}
+
+// roundRat rounds r to scale decimal digits using the rounding mode,
+// returning the decimal string.
+func roundRat(r *big.Rat, scale int, mode big.RoundingMode) string {
+	num := new(big.Int).Mul(r.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if m.Sign() != 0 {
+		neg := num.Sign() < 0
+		// compare twice the remainder with the denominator
+		c := new(big.Int).Lsh(m.Abs(m), 1).Cmp(r.Denom())
+		var away bool
+		switch mode {
+		case big.ToNearestEven:
+			away = c > 0 || c == 0 && q.Bit(0) == 1
+		case big.ToNearestAway:
+			away = c >= 0
+		case big.AwayFromZero:
+			away = true
+		case big.ToNegativeInf:
+			away = neg
+		case big.ToPositiveInf:
+			away = !neg
+		}
+		switch {
+		case away && neg:
+			q.Sub(q, big.NewInt(1))
+		case away:
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	var sign string
+	if q.Sign() < 0 {
+		sign = "-"
+	}
+	s := q.Abs(q).String()
+	if scale == 0 {
+		return sign + s
+	}
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// decimalText returns the text of a string or bytes value, when it is a
+// decimal number.
+func decimalText(v any) (string, bool) {
+	var s string
+	switch z := v.(type) {
+	case string:
+		s = z
+	case []byte:
+		s = string(z)
+	case sql.RawBytes:
+		s = string(z)
+	case sql.NullString:
+		if !z.Valid {
+			return "", false
+		}
+		s = z.String
+	default:
+		return "", false
+	}
+	return s, isDecimal(s)
+}
+
+// isDecimalType returns true when typ is a known arbitrary precision decimal
+// type, by name (ie, decimal.Decimal, decimal.NullDecimal, or pgtype.Numeric).
+func isDecimalType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch strings.ToLower(typ.Name()) {
+	case "decimal", "nulldecimal", "numeric", "dec":
+		return true
+	}
+	return false
+}
+
+// isNumericType returns true when typ is a numeric database type name.
+func isNumericType(typ string) bool {
+	switch strings.TrimSuffix(typ, " UNSIGNED") {
+	case "NUMERIC", "DECIMAL", "NUMBER", "DEC", "FIXED",
+		"INT", "INTEGER", "SMALLINT", "BIGINT", "TINYINT", "MEDIUMINT",
+		"INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL", "SMALLSERIAL",
+		"FLOAT", "FLOAT4", "FLOAT8", "REAL", "DOUBLE", "DOUBLE PRECISION",
+		"HUGEINT", "UBIGINT", "UINTEGER", "USMALLINT", "UTINYINT":
+		return true
+	}
+	return false
+}
+
+// numericValue creates a numeric value, with the width of its fraction.
+func (f *EscapeFormatter) numericValue(s string, align Align) *Value {
+	v := newValue(s, align, true)
+	v.Numeric = true
+	point := "."
+	if f.numericLocalePrinter != nil && f.numericPoint != "" {
+		point = f.numericPoint
+	}
+	if i := strings.LastIndex(s, point); i != -1 {
+		v.Fraction = len(s) - i
+	}
+	return v
+}
+
+// localizeDecimal formats the decimal string s using the separators of the
+// numeric locale printer.
+func (f *EscapeFormatter) localizeDecimal(s string) string {
+	group, point := f.numericGroup, f.numericPoint
+	if point == "" {
+		return s
+	}
+	var sign string
+	if s[0] == '-' || s[0] == '+' {
+		sign, s = s[:1], s[1:]
+	}
+	if sign == "+" {
+		sign = ""
+	}
+	var exp string
+	if i := strings.IndexAny(s, "eE"); i != -1 {
+		s, exp = s[:i], s[i:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i != 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		b.WriteString(point)
+		b.WriteString(frac)
+	}
+	b.WriteString(exp)
+	return b.String()
+}
+
+// isSQLNull returns true when typ is a generic sql.Null[T].
+func isSQLNull(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.PkgPath() == "database/sql" && strings.HasPrefix(typ.Name(), "Null[")
+}
+
+// isDecimal returns true when s is a decimal number, with an optional sign,
+// fraction, and exponent.
+func isDecimal(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	var digits, point, exp bool
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case '0' <= c && c <= '9':
+			digits = true
+		case c == '.' && !point && !exp:
+			point = true
+		case (c == 'e' || c == 'E') && digits && !exp:
+			exp, digits = true, false
+			if i+1 < len(s) && (s[i+1] == '-' || s[i+1] == '+') {
+				i++
+			}
+		default:
+			return false
+		}
+	}
+	return digits
 }
 
 // valueFromBuffer returns a value from a buffer known not to contain
@@ -458,6 +1466,12 @@ type Value struct {
 	// Quoted tracks whether or not a raw value should be quoted or not (ie,
 	// contains a space or non printable character).
 	Quoted bool
+	// Numeric indicates the value is a number, that can be aligned on its
+	// decimal separator.
+	Numeric bool
+	// Fraction is the width of the fractional part of a numeric value,
+	// including the decimal separator.
+	Fraction int
 }
 
 func (v *Value) String() string {
@@ -513,6 +1527,13 @@ func (a Align) String() string {
 	return fmt.Sprintf("Align(%d)", a)
 }
 
+// Aligned wraps a value with an alignment, used by [EscapeFormatter] in place
+// of the value's default alignment.
+type Aligned struct {
+	Value any
+	Align Align
+}
+
 // tabwidth returns the rune width of buf containing tabs from start position
 // in buf, a column offset, and given tab width.
 func tabwidth(tabs [][2]int, offset, tab int) int {
@@ -545,6 +1566,159 @@ func WithTimeFormat(timeFormat string) EscapeFormatterOption {
 	}
 }
 
+// Postgres (psql) time formats.
+const (
+	// PostgresDateFormat is the Postgres date format.
+	PostgresDateFormat = "2006-01-02"
+	// PostgresTimeFormat is the Postgres time format.
+	PostgresTimeFormat = "15:04:05.999999"
+	// PostgresTimestampFormat is the Postgres timestamp format.
+	PostgresTimestampFormat = "2006-01-02 15:04:05.999999"
+	// PostgresTimestamptzFormat is the Postgres timestamp with time zone
+	// format. When used by the escape formatter, the minutes (and seconds) of
+	// the offset are also formatted when not zero (ie, "+05:30").
+	PostgresTimestamptzFormat = "2006-01-02 15:04:05.999999-07"
+)
+
+// WithTimeFormats is an escape formatter option to set the time formats used
+// for time values of date, time, timestamp, and timestamp with time zone
+// columns, as determined by the column's database type name. The time format
+// (see [WithTimeFormat]) is used for empty formats, and for the values of
+// columns of other or unknown types.
+func WithTimeFormats(date, timeOfDay, timestamp, timestamptz string) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.dateFormat, f.timeOfDayFormat = date, timeOfDay
+		f.timestampFormat, f.timestamptzFormat = timestamp, timestamptz
+	}
+}
+
+// WithPostgresTimeFormats is an escape formatter option to use the Postgres
+// (psql) time formats for time values.
+func WithPostgresTimeFormats() EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.timeFormat = PostgresTimestamptzFormat
+		f.dateFormat, f.timeOfDayFormat = PostgresDateFormat, PostgresTimeFormat
+		f.timestampFormat, f.timestamptzFormat = PostgresTimestampFormat, PostgresTimestamptzFormat
+	}
+}
+
+// DurationFormat is a duration format.
+type DurationFormat int
+
+// DurationFormat values.
+const (
+	// DurationDefault formats durations using [time.Duration.String] (ie,
+	// "26h3m4.5s").
+	DurationDefault DurationFormat = iota
+	// DurationPostgres formats durations the same as a Postgres interval (ie,
+	// "1 day 02:03:04.5").
+	DurationPostgres
+	// DurationSeconds formats durations as a number of seconds (ie, "93784.5").
+	DurationSeconds
+)
+
+// WithDurationFormat is an escape formatter option to set the format used for
+// duration values.
+func WithDurationFormat(durationFormat DurationFormat) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.durationFormat = durationFormat
+	}
+}
+
+// WithJSONPretty is an escape formatter option to re-indent JSON objects and
+// arrays stored as text (ie, the values of JSON columns), using the JSON
+// prefix and indent (see [WithJSONConfig]). Nesting deeper than the max depth
+// is written on a single line, unless the max depth is 0 or less. Not used
+// when escaping JSON or raw (csv) values.
+func WithJSONPretty(pretty bool, maxDepth int) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.jsonPretty, f.jsonMaxDepth = pretty, maxDepth
+	}
+}
+
+// WithJSONHighlight is an escape formatter option to highlight the keys,
+// strings, and numbers of re-indented JSON text (see [WithJSONPretty]) using
+// ANSI colors, for use with color capable terminals. The color escape
+// sequences are not included in value widths.
+func WithJSONHighlight(highlight bool) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.jsonHighlight = highlight
+	}
+}
+
+// WithNullText is an escape formatter option to set the text used for NULL
+// values in composite values, such as changed diff values (see [DiffValue]).
+// Defaults to "NULL".
+func WithNullText(null string) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.null = null
+	}
+}
+
+// WithDiffHighlight is an escape formatter option to highlight the old and new
+// values of changed diff values (see [DiffValue]) using ANSI colors, for use
+// with color capable terminals. The color escape sequences are not included in
+// value widths.
+func WithDiffHighlight(highlight bool) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.diffHighlight = highlight
+	}
+}
+
+// BinaryFormat is a binary data format.
+type BinaryFormat int
+
+// BinaryFormat values.
+const (
+	// BinaryDefault formats binary data the same as text, escaping invalid
+	// runes.
+	BinaryDefault BinaryFormat = iota
+	// BinaryHex formats binary data using the Postgres hex format (ie,
+	// "\x0102").
+	BinaryHex
+	// BinaryBase64 formats binary data using standard base64 encoding.
+	BinaryBase64
+	// BinaryEscape formats binary data using the Postgres bytea escape format
+	// (ie, "a\001").
+	BinaryEscape
+	// BinaryPreview formats binary data using the Postgres hex format,
+	// truncated to a number of bytes (see [WithBinaryPreview]) and followed
+	// by the length.
+	BinaryPreview
+	// BinaryHexdump formats binary data as a multi-line hexdump, for use with
+	// expanded output.
+	BinaryHexdump
+)
+
+// WithBinaryFormat is an escape formatter option to set the format of binary
+// data, for the named columns or all columns when no columns are specified.
+//
+// Bytes are binary data when the column has a binary database type (ie,
+// BYTEA, BLOB), or when the column type is not known and the bytes are not
+// valid UTF-8 text.
+func WithBinaryFormat(format BinaryFormat, columns ...string) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		if len(columns) == 0 {
+			f.binaryFormat = format
+			return
+		}
+		if f.columnBinaryFormats == nil {
+			f.columnBinaryFormats = make(map[string]BinaryFormat)
+		}
+		for _, col := range columns {
+			f.columnBinaryFormats[col] = format
+		}
+	}
+}
+
+// WithBinaryPreview is an escape formatter option to set the number of bytes
+// of binary data previews (see [BinaryPreview]). A negative n is treated as 0.
+func WithBinaryPreview(n int) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.binaryPreview = max(n, 0)
+	}
+}
+
 // WithTimeLocation is an escape formatter option to set the time location used
 // for time values.
 func WithTimeLocation(timeLocation *time.Location) EscapeFormatterOption {
@@ -619,6 +1793,162 @@ func WithNumericLocale(enable bool, locale string) EscapeFormatterOption {
 				tag = t
 			}
 			f.numericLocalePrinter = message.NewPrinter(tag)
+			// determine separators
+			sep := []rune(f.numericLocalePrinter.Sprintf("%v", number.Decimal(1234.5, number.MinFractionDigits(1))))
+			switch len(sep) {
+			case 7:
+				f.numericGroup, f.numericPoint = string(sep[1]), string(sep[5])
+			case 6:
+				f.numericGroup, f.numericPoint = "", string(sep[4])
+			}
+		}
+	}
+}
+
+// WithNumericScale is an escape formatter option to set the number of decimal
+// digits arbitrary precision numbers (big.Int, big.Float, big.Rat, and decimal
+// strings of numeric columns) are rounded to, using the rounding mode. Not
+// rounded when scale is less than 0.
+func WithNumericScale(scale int, rounding big.RoundingMode) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		f.scale, f.rounding = scale, rounding
+	}
+}
+
+// Notation is a numeric notation.
+type Notation int
+
+// Notation values.
+const (
+	// NotationDefault uses scientific notation for floats with large
+	// exponents.
+	NotationDefault Notation = iota
+	// NotationScientific always uses scientific notation.
+	NotationScientific
+	// NotationPlain never uses scientific notation.
+	NotationPlain
+)
+
+// ByteSize is the units of a byte size.
+type ByteSize int
+
+// ByteSize values.
+const (
+	// ByteSizeNone formats numbers as is.
+	ByteSizeNone ByteSize = iota
+	// ByteSizeBinary formats numbers as byte sizes using binary (1024) units
+	// (ie, 1.5 GiB).
+	ByteSizeBinary
+	// ByteSizeSI formats numbers as byte sizes using SI (1000) units (ie, 1.5
+	// GB).
+	ByteSizeSI
+)
+
+// withNumberFormat returns an escape formatter option applying a change to
+// the number format of the columns, or of all columns when no columns are
+// specified.
+func withNumberFormat(apply func(*numberFormat), columns []string) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		if len(columns) == 0 {
+			apply(&f.numberFormat)
+			return
+		}
+		if f.columnNumberFormats == nil {
+			f.columnNumberFormats = make(map[string]*numberFormat)
+		}
+		for _, col := range columns {
+			nf, ok := f.columnNumberFormats[col]
+			if !ok {
+				nf = &numberFormat{decimals: -1}
+				f.columnNumberFormats[col] = nf
+			}
+			apply(nf)
+		}
+	}
+}
+
+// WithDecimals is an escape formatter option to format numbers with a fixed
+// number of decimal places, for the named columns or all columns when no
+// columns are specified. For arbitrary precision numbers, takes precedence
+// over the scale (see [WithNumericScale]).
+func WithDecimals(decimals int, columns ...string) EscapeFormatterOption {
+	return withNumberFormat(func(nf *numberFormat) {
+		nf.decimals = decimals
+	}, columns)
+}
+
+// WithSignificantDigits is an escape formatter option to format numbers with
+// a number of significant digits, for the named columns or all columns when
+// no columns are specified.
+func WithSignificantDigits(digits int, columns ...string) EscapeFormatterOption {
+	return withNumberFormat(func(nf *numberFormat) {
+		nf.digits = digits
+	}, columns)
+}
+
+// WithNotation is an escape formatter option to set the notation of numbers,
+// for the named columns or all columns when no columns are specified.
+func WithNotation(notation Notation, columns ...string) EscapeFormatterOption {
+	return withNumberFormat(func(nf *numberFormat) {
+		nf.notation = notation
+	}, columns)
+}
+
+// WithPercent is an escape formatter option to format numbers as percentages
+// (ie, 0.5 as 50%), for the named columns or all columns when no columns are
+// specified.
+func WithPercent(percent bool, columns ...string) EscapeFormatterOption {
+	return withNumberFormat(func(nf *numberFormat) {
+		nf.percent = percent
+	}, columns)
+}
+
+// WithByteSize is an escape formatter option to format numbers as byte sizes
+// (ie, 1610612736 as 1.5 GiB), for the named columns or all columns when no
+// columns are specified.
+func WithByteSize(size ByteSize, columns ...string) EscapeFormatterOption {
+	return withNumberFormat(func(nf *numberFormat) {
+		nf.size = size
+	}, columns)
+}
+
+// TypeValue is a value formatted by a registered type formatter (see
+// [WithTypeFormatter]).
+type TypeValue struct {
+	// Text is the formatted text.
+	Text string
+	// Align is the alignment.
+	Align Align
+	// Raw indicates the text does not need escaping.
+	Raw bool
+	// Quoted indicates the text is to be quoted when encoding raw (csv)
+	// values.
+	Quoted bool
+}
+
+// TypeFormatterFunc is a func that formats a value of a registered type.
+type TypeFormatterFunc func(any) (TypeValue, error)
+
+// WithTypeFormatter is an escape formatter option to register a type
+// formatter for values of the type, taking precedence over the built-in
+// formatting. When the type is an interface, the formatter is used for
+// values implementing the interface (including with pointer receivers, when
+// the formatter is passed a pointer to a copy of the value), checked in the
+// order registered. A nil type or func is ignored.
+//
+// Unless the formatted value is raw, the formatted text is escaped the same
+// as any other string value.
+func WithTypeFormatter(typ reflect.Type, fn TypeFormatterFunc) EscapeFormatterOption {
+	return func(f *EscapeFormatter) {
+		switch {
+		case typ == nil || fn == nil:
+		case typ.Kind() == reflect.Interface:
+			f.interfaceFormatters = append(f.interfaceFormatters, interfaceFormatter{typ: typ, f: fn})
+		default:
+			if f.typeFormatters == nil {
+				f.typeFormatters = make(map[reflect.Type]TypeFormatterFunc)
+			}
+			f.typeFormatters[typ] = fn
 		}
 	}
 }
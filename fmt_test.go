@@ -1,15 +1,22 @@
 package tblfmt
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
 	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	runewidth "github.com/mattn/go-runewidth"
+	"github.com/xo/tblfmt/internal"
 )
 
 func TestTabwidthCalc(t *testing.T) {
@@ -171,6 +178,447 @@ func TestFormatBytesRaw(t *testing.T) {
 	}
 }
 
+// decimal is a driver.Valuer returning a decimal string.
+type decimal string
+
+func (d decimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+// code is a driver.Valuer returning a string code.
+type code string
+
+func (c code) Value() (driver.Value, error) {
+	return string(c), nil
+}
+
+func TestFormatValuer(t *testing.T) {
+	tests := []struct {
+		v     any
+		exp   string
+		align Align
+		opts  []EscapeFormatterOption
+	}{
+		{sql.Null[int64]{V: 15, Valid: true}, "15", AlignRight, nil},
+		{sql.Null[string]{V: "foo", Valid: true}, "foo", AlignLeft, nil},
+		{sql.Null[uint8]{}, "", AlignLeft, nil},
+		{decimal("-1234567.125"), "-1234567.125", AlignRight, nil},
+		{decimal("-1234567.125"), "-1,234,567.125", AlignRight, []EscapeFormatterOption{WithNumericLocale(true, "en-US")}},
+		{decimal("1234567.125"), "1.234.567,125", AlignRight, []EscapeFormatterOption{WithNumericLocale(true, "de-DE")}},
+		{decimal("1e10"), "1e10", AlignRight, nil},
+		{decimal("abc"), "abc", AlignLeft, nil},
+		{code("00123"), "00123", AlignLeft, nil},
+		{code("1234567"), "1234567", AlignLeft, []EscapeFormatterOption{WithNumericLocale(true, "en-US")}},
+	}
+	for i, test := range tests {
+		vals, err := NewEscapeFormatter(test.opts...).Format([]any{test.v})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if test.exp == "" {
+			if vals[0] != nil {
+				t.Errorf("test %d expected nil, got: %v", i, vals[0])
+			}
+			continue
+		}
+		if s := vals[0].String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if vals[0].Align != test.align {
+			t.Errorf("test %d expected align %v, got: %v", i, test.align, vals[0].Align)
+		}
+	}
+}
+
+func TestFormatTypeFormatter(t *testing.T) {
+	ip := func(v any) (TypeValue, error) {
+		return TypeValue{Text: v.(net.IP).String(), Align: AlignCenter, Raw: true}, nil
+	}
+	stringer := func(v any) (TypeValue, error) {
+		return TypeValue{Text: "<" + v.(fmt.Stringer).String() + ">\n"}, nil
+	}
+	f := NewEscapeFormatter(
+		WithTypeFormatter(reflect.TypeFor[net.IP](), ip),
+		WithTypeFormatter(reflect.TypeFor[fmt.Stringer](), stringer),
+	)
+	vals, err := f.Format([]any{net.IPv4(127, 0, 0, 1), time.Second, 15, new(any)})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := vals[0].String(); s != "127.0.0.1" || vals[0].Align != AlignCenter || !vals[0].Raw {
+		t.Errorf("expected %q centered and raw, got: %q %v %t", "127.0.0.1", s, vals[0].Align, vals[0].Raw)
+	}
+	if s := vals[1].String(); s != "<1s>\n" || len(vals[1].Newlines) != 1 {
+		t.Errorf("expected %q with a newline, got: %q %v", "<1s>\n", s, vals[1].Newlines)
+	}
+	if s := vals[2].String(); s != "15" || vals[2].Align != AlignRight {
+		t.Errorf("expected %q right aligned, got: %q %v", "15", s, vals[2].Align)
+	}
+	if vals[3] != nil {
+		t.Errorf("expected nil, got: %v", vals[3])
+	}
+	// pointer types and pointer receivers
+	bigInt := func(v any) (TypeValue, error) {
+		return TypeValue{Text: "big " + v.(*big.Int).String()}, nil
+	}
+	ptr := func(v any) (TypeValue, error) {
+		return TypeValue{Text: "ptr " + v.(*ptrStringer).String()}, nil
+	}
+	f = NewEscapeFormatter(
+		WithTypeFormatter(reflect.TypeFor[*big.Int](), bigInt),
+		WithTypeFormatter(reflect.TypeFor[interface{ Ptr() }](), ptr),
+		WithTypeFormatter(nil, ptr),
+	)
+	var x, y any = big.NewInt(7), (*big.Int)(nil)
+	vals, err = f.Format([]any{big.NewInt(5), &x, ptrStringer("a"), &y})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, exp := range []string{"big 5", "big 7", "ptr a"} {
+		if s := vals[i].String(); s != exp {
+			t.Errorf("value %d expected %q, got: %q", i, exp, s)
+		}
+	}
+	if vals[3] != nil {
+		t.Errorf("expected nil, got: %v", vals[3])
+	}
+}
+
+// ptrStringer has methods with a pointer receiver.
+type ptrStringer string
+
+func (s *ptrStringer) Ptr() {}
+
+func (s *ptrStringer) String() string {
+	return string(*s)
+}
+
+func TestFormatNumeric(t *testing.T) {
+	big1, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	big2, _ := new(big.Float).SetPrec(200).SetString("12345.678901234567890123456789")
+	tests := []struct {
+		v    any
+		exp  string
+		opts []EscapeFormatterOption
+	}{
+		{big1, "-123456789012345678901234567890", nil},
+		{big1, "-123,456,789,012,345,678,901,234,567,890", []EscapeFormatterOption{WithNumericLocale(true, "en-US")}},
+		{big1, "-123456789012345678901234567890.00", []EscapeFormatterOption{WithNumericScale(2, big.ToNearestEven)}},
+		{big2, "12345.678901234567890123456789", nil},
+		{big2, "12.345,68", []EscapeFormatterOption{WithNumericScale(2, big.ToNearestEven), WithNumericLocale(true, "de-DE")}},
+		{big.NewRat(1, 8), "0.125", nil},
+		{big.NewRat(1, 8), "0.12", []EscapeFormatterOption{WithNumericScale(2, big.ToNearestEven)}},
+		{big.NewRat(3, 8), "0.38", []EscapeFormatterOption{WithNumericScale(2, big.ToNearestEven)}},
+		{big.NewRat(-1, 8), "-0.13", []EscapeFormatterOption{WithNumericScale(2, big.ToNearestAway)}},
+		{big.NewRat(-1, 8), "-0.12", []EscapeFormatterOption{WithNumericScale(2, big.ToZero)}},
+		{big.NewRat(-1, 8), "-0.13", []EscapeFormatterOption{WithNumericScale(2, big.ToNegativeInf)}},
+		{big.NewRat(-1, 8), "-0.12", []EscapeFormatterOption{WithNumericScale(2, big.ToPositiveInf)}},
+		{big.NewRat(-1, 1000), "0", []EscapeFormatterOption{WithNumericScale(0, big.ToNearestEven)}},
+		{big.NewRat(1, 3), "0.3333333333333333", nil},
+		{decimal("2.5"), "2", []EscapeFormatterOption{WithNumericScale(0, big.ToNearestEven)}},
+	}
+	for i, test := range tests {
+		vals, err := NewEscapeFormatter(test.opts...).Format([]any{test.v})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := vals[0].String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if vals[0].Align != AlignRight || !vals[0].Raw {
+			t.Errorf("test %d expected right aligned and raw, got: %v %t", i, vals[0].Align, vals[0].Raw)
+		}
+	}
+	// numeric columns
+	types, err := newColumnTypes([]columnType{
+		{name: "a", typ: reflect.TypeFor[string](), dbType: "NUMERIC(30,2)"},
+		{name: "b", typ: reflect.TypeFor[string](), dbType: "TEXT"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	f := NewEscapeFormatter(WithNumericLocale(true, "en-US")).ForColumnTypes([]string{"a", "b"}, types)
+	vals, err := f.Format([]any{[]byte("1234567.50"), []byte("1234567.50")})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := vals[0].String(); s != "1,234,567.50" || vals[0].Align != AlignRight {
+		t.Errorf("expected %q right aligned, got: %q %v", "1,234,567.50", s, vals[0].Align)
+	}
+	if s := vals[1].String(); s != "1234567.50" || vals[1].Align != AlignLeft {
+		t.Errorf("expected %q left aligned, got: %q %v", "1234567.50", s, vals[1].Align)
+	}
+	// shared formatter is not changed
+	shared := NewEscapeFormatter(WithNumericLocale(true, "en-US"))
+	_ = shared.ForColumnTypes([]string{"a", "b"}, types)
+	if vals, err = shared.Format([]any{[]byte("1234567.50"), code("1234567")}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := vals[0].String(); s != "1234567.50" || vals[0].Align != AlignLeft {
+		t.Errorf("expected %q left aligned, got: %q %v", "1234567.50", s, vals[0].Align)
+	}
+	// numeric column valuer
+	if vals, err = shared.ForColumnTypes([]string{"a"}, types).Format([]any{code("1234567")}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := vals[0].String(); s != "1,234,567" || vals[0].Align != AlignRight {
+		t.Errorf("expected %q right aligned, got: %q %v", "1,234,567", s, vals[0].Align)
+	}
+}
+
+func TestFormatNumberFormat(t *testing.T) {
+	a, b := 0.1, 0.2
+	tests := []struct {
+		v    any
+		exp  string
+		opts []EscapeFormatterOption
+	}{
+		{a + b, "0.30000000000000004", nil},
+		{a + b, "0.30", []EscapeFormatterOption{WithDecimals(2)}},
+		{1234567.891, "1.23e+06", []EscapeFormatterOption{WithSignificantDigits(3)}},
+		{1234567.891, "1230000", []EscapeFormatterOption{WithSignificantDigits(3), WithNotation(NotationPlain)}},
+		{1e21, "1000000000000000000000", []EscapeFormatterOption{WithNotation(NotationPlain)}},
+		{1234.5, "1.2345e+03", []EscapeFormatterOption{WithNotation(NotationScientific)}},
+		{1234.5, "1.23e+03", []EscapeFormatterOption{WithNotation(NotationScientific), WithDecimals(2)}},
+		{1234567.891, "1,234,567.9", []EscapeFormatterOption{WithDecimals(1), WithNumericLocale(true, "en-US")}},
+		{0.125, "12.5%", []EscapeFormatterOption{WithPercent(true)}},
+		{sql.NullFloat64{Float64: 0.5, Valid: true}, "50,0%", []EscapeFormatterOption{WithPercent(true), WithDecimals(1), WithNumericLocale(true, "de-DE")}},
+		{int64(1000000), "1000000", []EscapeFormatterOption{WithSignificantDigits(5)}},
+		{1610612736, "1.5 GiB", []EscapeFormatterOption{WithByteSize(ByteSizeBinary)}},
+		{1500, "1.5 kB", []EscapeFormatterOption{WithByteSize(ByteSizeSI)}},
+		{uint16(512), "512 B", []EscapeFormatterOption{WithByteSize(ByteSizeBinary)}},
+		{int64(-3 << 20), "-3.00 MiB", []EscapeFormatterOption{WithByteSize(ByteSizeBinary), WithDecimals(2)}},
+		{big.NewRat(1, 3), "0.333", []EscapeFormatterOption{WithDecimals(3)}},
+	}
+	for i, test := range tests {
+		vals, err := NewEscapeFormatter(test.opts...).Format([]any{test.v})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := vals[0].String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if vals[0].Align != AlignRight {
+			t.Errorf("test %d expected right aligned, got: %v", i, vals[0].Align)
+		}
+	}
+	// per column
+	rs := internal.New([]string{"name", "size", "ratio"}, [][]any{
+		{"a", 1536, 0.25},
+		{"b", 10, 1.0 / 3},
+	})
+	exp := ` name |  size   | ratio  
+------+---------+--------
+ a    | 1.5 KiB | 25.00% 
+ b    |    10 B | 33.33% 
+(2 rows)
+`
+	buf := new(strings.Builder)
+	opts := WithFormatterOptions(
+		WithByteSize(ByteSizeBinary, "size"),
+		WithPercent(true, "ratio"),
+		WithDecimals(2, "ratio"),
+	)
+	if err := EncodeTable(buf, rs, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := buf.String(); s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	types, err := newColumnTypes([]columnType{
+		{name: "d", typ: reflect.TypeFor[time.Time](), dbType: "DATE"},
+		{name: "t", typ: reflect.TypeFor[time.Time](), dbType: "TIME"},
+		{name: "ts", typ: reflect.TypeFor[time.Time](), dbType: "TIMESTAMP"},
+		{name: "tstz", typ: reflect.TypeFor[time.Time](), dbType: "TIMESTAMP WITH TIME ZONE"},
+		{name: "x", typ: reflect.TypeFor[time.Time](), dbType: "TEXT"},
+		{name: "dur", typ: reflect.TypeFor[int64](), dbType: "INTERVAL"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	loc := time.FixedZone("", -7*3600)
+	v := time.Date(2024, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	d := 26*time.Hour + 3*time.Minute + 4*time.Second + 500*time.Millisecond
+	tests := []struct {
+		opts []EscapeFormatterOption
+		exp  []string
+	}{
+		{nil, []string{"2024-01-02", "15:04:05.123456", "2024-01-02T15:04:05.123456Z", "2024-01-02T15:04:05.123456Z", "2024-01-02T15:04:05.123456Z", "26h3m4.5s"}},
+		{
+			[]EscapeFormatterOption{WithPostgresTimeFormats(), WithTimeLocation(loc), WithDurationFormat(DurationPostgres)},
+			[]string{"2024-01-02", "15:04:05.123456", "2024-01-02 15:04:05.123456", "2024-01-02 08:04:05.123456-07", "2024-01-02 08:04:05.123456-07", "1 day 02:03:04.5"},
+		},
+		{
+			[]EscapeFormatterOption{WithTimeFormats("Jan 2", "", "", time.Kitchen), WithDurationFormat(DurationSeconds)},
+			[]string{"Jan 2", "2024-01-02T15:04:05.123456Z", "2024-01-02T15:04:05.123456Z", "3:04PM", "2024-01-02T15:04:05.123456Z", "93784.5"},
+		},
+	}
+	for i, test := range tests {
+		f := NewEscapeFormatter(test.opts...).ForColumnTypes([]string{"d", "t", "ts", "tstz", "x", "dur"}, types)
+		vals, err := f.Format([]any{v, v, v, v, v, d})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		for j, exp := range test.exp {
+			if s := vals[j].String(); s != exp {
+				t.Errorf("test %d column %d expected %q, got: %q", i, j, exp, s)
+			}
+		}
+		if vals[5].Align != AlignRight {
+			t.Errorf("test %d expected duration right aligned, got: %v", i, vals[5].Align)
+		}
+	}
+	for i, test := range []struct {
+		d   time.Duration
+		exp string
+	}{
+		{0, "00:00:00"},
+		{-90 * time.Minute, "-01:30:00"},
+		{-49 * time.Hour, "-2 days -01:00:00"},
+		{72*time.Hour + time.Microsecond, "3 days 00:00:00.000001"},
+	} {
+		if s := postgresDuration(test.d); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+	for i, test := range []struct {
+		off int
+		exp string
+	}{
+		{0, "2024-01-02 15:04:05.123456+00"},
+		{5*3600 + 30*60, "2024-01-02 20:34:05.123456+05:30"},
+		{-(3*3600 + 30*60), "2024-01-02 11:34:05.123456-03:30"},
+		{53*60 + 28, "2024-01-02 15:57:33.123456+00:53:28"},
+	} {
+		f := NewEscapeFormatter(WithPostgresTimeFormats(), WithTimeLocation(time.FixedZone("", test.off)))
+		vals, err := f.Format([]any{v})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := vals[0].String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
+
+func TestFormatBinary(t *testing.T) {
+	bin := []byte{0, 'a', '\\', 0xff}
+	tests := []struct {
+		format BinaryFormat
+		exp    string
+	}{
+		{BinaryDefault, `\x00a\\xff`},
+		{BinaryHex, `\x00615cff`},
+		{BinaryBase64, "AGFc/w=="},
+		{BinaryEscape, `\000a\\\377`},
+		{BinaryPreview, `\x0061... (4 bytes)`},
+		{BinaryHexdump, "00000000  00 61 5c ff                                       |.a\\.|"},
+	}
+	for i, test := range tests {
+		vals, err := NewEscapeFormatter(WithBinaryFormat(test.format), WithBinaryPreview(2)).Format([]any{bin, []byte("text\n")})
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := vals[0].String(); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if s := vals[1].String(); s != "text\n" {
+			t.Errorf("test %d expected text %q, got: %q", i, "text\n", s)
+		}
+	}
+	// column types
+	types, err := newColumnTypes([]columnType{
+		{name: "a", typ: reflect.TypeFor[[]byte](), dbType: "BYTEA"},
+		{name: "b", typ: reflect.TypeFor[[]byte](), dbType: "VARCHAR(10)"},
+		{name: "c", typ: reflect.TypeFor[[]byte](), dbType: "BLOB"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	f := NewEscapeFormatter(WithBinaryFormat(BinaryBase64), WithBinaryFormat(BinaryHex, "c")).ForColumnTypes([]string{"a", "b", "c"}, types)
+	vals, err := f.Format([]any{[]byte("abc"), sql.RawBytes("ab\x01"), []byte("abc")})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, exp := range []string{"YWJj", `ab\x01`, `\x616263`} {
+		if s := vals[i].String(); s != exp {
+			t.Errorf("column %d expected %q, got: %q", i, exp, s)
+		}
+	}
+	// negative preview
+	vals, err = NewEscapeFormatter(WithBinaryFormat(BinaryPreview), WithBinaryPreview(-1)).Format([]any{bin})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s, exp := vals[0].String(), `\x... (4 bytes)`; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+func TestFormatJSONText(t *testing.T) {
+	rs := func() ResultSet {
+		return internal.New([]string{"id", "doc"}, [][]any{
+			{1, []byte(`{"a": 1, "b": {"c": [true, null]}, "d": []}`)},
+			{2, `[1,"x"]`},
+			{3, "{not json"},
+		})
+	}
+	exp := ` id |     doc     
+----+-------------
+  1 | {          +
+    |   "a": 1,  +
+    |   "b": {   +
+    |     "c": [ +
+    |       true,+
+    |       null +
+    |     ]      +
+    |   },       +
+    |   "d": []  +
+    | } 
+  2 | [          +
+    |   1,       +
+    |   "x"      +
+    | ] 
+  3 | {not json 
+(3 rows)
+`
+	buf := new(strings.Builder)
+	if err := EncodeTable(buf, rs(), WithFormatterOptions(WithJSONPretty(true, 0))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := buf.String(); s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+	exp = ` id |            doc            
+----+---------------------------
+  1 | {                        +
+    |   "a": 1,                +
+    |   "b": {"c":[true,null]},+
+    |   "d": []                +
+    | } 
+  2 | [                        +
+    |   1,                     +
+    |   "x"                    +
+    | ] 
+  3 | {not json 
+(3 rows)
+`
+	buf.Reset()
+	if err := EncodeTable(buf, rs(), WithFormatterOptions(WithJSONPretty(true, 1), WithJSONHighlight(true))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(buf.String(), "")
+	if s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+	if !strings.Contains(buf.String(), jsonKeyColor+`"a"`+resetColor+": "+jsonNumberColor+"1"+resetColor) {
+		t.Errorf("expected highlighted key and number, got:\n%q", buf.String())
+	}
+}
+
 type escTest struct {
 	s     string
 	exp   *Value
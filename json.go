@@ -0,0 +1,301 @@
+package tblfmt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSONResultSet is a result set for JSON data, read using the standard
+// [encoding/json.Decoder]. Each reader is a separate result set.
+type JSONResultSet struct {
+	// readers are the readers.
+	readers []io.Reader
+	// names are the supplied column names.
+	names []string
+	// sample is the number of rows used to discover columns.
+	sample int
+	// dec is the current decoder.
+	dec *json.Decoder
+	// array indicates the rows are contained in a top-level array.
+	array bool
+	// cols are the current columns.
+	cols []string
+	// index is the index of the columns.
+	index map[string]int
+	// rows are the buffered rows.
+	rows []jsonRow
+	// row is the current row.
+	row []any
+	// err is the last encountered error.
+	err error
+}
+
+// FromJSON creates a result set for JSON data read from the readers, with each
+// reader being a separate result set.
+//
+// The data can either be a top-level array of rows, or a stream of rows (ie,
+// JSON Lines). Rows are either objects or arrays. Data starting with "[" is
+// read as a top-level array when followed by "{" or "[", and otherwise as a
+// stream of array rows. Nested objects and arrays are returned as
+// map[string]any and []any values, and numbers are returned as int64 or
+// float64 values.
+//
+// Columns are discovered from the keys of the objects in the first 100 rows,
+// in the order first seen. Use [WithSampleRows] to change the number of rows
+// (buffered in memory) used to discover columns, or [WithColumnNames] to
+// supply the columns. Column names must be supplied when the rows are arrays.
+func FromJSON(readers []io.Reader, opts ...Option) (*JSONResultSet, error) {
+	r := &JSONResultSet{
+		readers: readers,
+		sample:  100,
+	}
+	for _, o := range opts {
+		if err := o.apply(r); err != nil {
+			return nil, err
+		}
+	}
+	if len(readers) == 0 {
+		return nil, ErrResultSetIsNil
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the first reader, discovering the columns.
+func (r *JSONResultSet) open() error {
+	r.dec = json.NewDecoder(r.readers[0])
+	r.dec.UseNumber()
+	r.readers, r.array, r.rows, r.cols, r.index = r.readers[1:], false, nil, nil, make(map[string]int)
+	// read first row
+	first, err := r.first()
+	switch {
+	case err == io.EOF:
+		r.dec = nil
+	case err != nil:
+		return err
+	default:
+		r.rows = append(r.rows, first)
+	}
+	// buffer rows
+	if r.names == nil && r.sample != 0 {
+		for n := r.sample - 1; r.dec != nil && n != 0; n-- {
+			row, err := r.read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			r.rows = append(r.rows, row)
+		}
+	}
+	// discover columns
+	for _, name := range r.names {
+		r.add(name)
+	}
+	for _, row := range r.rows {
+		if row.keys == nil && r.names == nil {
+			return ErrJSONArrayRowsRequireColumnNames
+		}
+		if r.names == nil {
+			for _, key := range row.keys {
+				r.add(key)
+			}
+		}
+	}
+	if len(r.cols) == 0 {
+		return ErrResultSetHasNoColumns
+	}
+	return nil
+}
+
+// first reads the first row, determining if the rows are contained in a
+// top-level array. A leading "[" followed by "{" or "[" is a top-level array,
+// while a leading "[]" is an empty top-level array only when no rows follow.
+func (r *JSONResultSet) first() (jsonRow, error) {
+	tok, err := r.dec.Token()
+	switch {
+	case err != nil:
+		return jsonRow{}, err
+	case tok == json.Delim('{'):
+		return r.readDelim(tok.(json.Delim))
+	case tok != json.Delim('['):
+		return jsonRow{}, ErrInvalidJSONRow
+	case !r.dec.More():
+		if _, err := r.dec.Token(); err != nil {
+			return jsonRow{}, err
+		}
+		// empty top-level array
+		if !r.dec.More() {
+			return jsonRow{}, io.EOF
+		}
+		// stream of rows, with the first row being an empty array
+		return jsonRow{}, nil
+	}
+	if tok, err = r.dec.Token(); err != nil {
+		return jsonRow{}, err
+	}
+	if d, ok := tok.(json.Delim); ok {
+		r.array = true
+		return r.readDelim(d)
+	}
+	// stream of arrays, with tok being the first value
+	row, err := r.readDelim(json.Delim('['))
+	row.vals = append([]any{jsonValue(tok)}, row.vals...)
+	return row, err
+}
+
+// read reads a row.
+func (r *JSONResultSet) read() (jsonRow, error) {
+	if r.array && !r.dec.More() {
+		if _, err := r.dec.Token(); err != nil {
+			return jsonRow{}, err
+		}
+		return jsonRow{}, io.EOF
+	}
+	tok, err := r.dec.Token()
+	if err != nil {
+		return jsonRow{}, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok || (d != '{' && d != '[') {
+		return jsonRow{}, ErrInvalidJSONRow
+	}
+	return r.readDelim(d)
+}
+
+// readDelim reads the remainder of an object or array row.
+func (r *JSONResultSet) readDelim(d json.Delim) (jsonRow, error) {
+	var row jsonRow
+	if d == '{' {
+		row.keys = make([]string, 0)
+	}
+	for r.dec.More() {
+		if d == '{' {
+			tok, err := r.dec.Token()
+			if err != nil {
+				return jsonRow{}, err
+			}
+			row.keys = append(row.keys, tok.(string))
+		}
+		var v any
+		if err := r.dec.Decode(&v); err != nil {
+			return jsonRow{}, err
+		}
+		row.vals = append(row.vals, jsonValue(v))
+	}
+	if _, err := r.dec.Token(); err != nil {
+		return jsonRow{}, err
+	}
+	return row, nil
+}
+
+// add adds a column.
+func (r *JSONResultSet) add(name string) {
+	if _, ok := r.index[name]; !ok {
+		r.index[name] = len(r.cols)
+		r.cols = append(r.cols, name)
+	}
+}
+
+// Next satisfies the ResultSet interface.
+func (r *JSONResultSet) Next() bool {
+	var row jsonRow
+	switch {
+	case r.err != nil:
+		return false
+	case len(r.rows) != 0:
+		row, r.rows = r.rows[0], r.rows[1:]
+	case r.dec == nil:
+		r.row = nil
+		return false
+	default:
+		var err error
+		if row, err = r.read(); err != nil {
+			if err != io.EOF {
+				r.err = err
+			}
+			r.dec, r.row = nil, nil
+			return false
+		}
+	}
+	r.row = make([]any, len(r.cols))
+	if row.keys == nil {
+		copy(r.row, row.vals)
+		return true
+	}
+	for i, key := range row.keys {
+		if j, ok := r.index[key]; ok {
+			r.row[j] = row.vals[i]
+		}
+	}
+	return true
+}
+
+// Scan satisfies the ResultSet interface.
+func (r *JSONResultSet) Scan(dest ...any) error {
+	if r.row == nil {
+		return sql.ErrNoRows
+	}
+	if len(dest) != len(r.cols) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.cols), len(dest))
+	}
+	for i := range dest {
+		if err := assign(dest[i], r.row[i], -1); err != nil {
+			return fmt.Errorf("column %d (%s): %w", i, r.cols[i], err)
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (r *JSONResultSet) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (r *JSONResultSet) Close() error {
+	r.dec, r.readers, r.rows = nil, nil, nil
+	return nil
+}
+
+// Err satisfies the ResultSet interface.
+func (r *JSONResultSet) Err() error {
+	return r.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (r *JSONResultSet) NextResultSet() bool {
+	if r.err != nil || len(r.readers) == 0 {
+		return false
+	}
+	if r.err = r.open(); r.err != nil {
+		return false
+	}
+	return true
+}
+
+// jsonRow is a decoded json row.
+type jsonRow struct {
+	// keys are the object keys, or nil for an array row.
+	keys []string
+	// vals are the values.
+	vals []any
+}
+
+// jsonValue converts a top-level json number to an int64 or float64.
+func jsonValue(v any) any {
+	if n, ok := v.(json.Number); ok {
+		if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(string(n), 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,143 @@
+package tblfmt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s    string
+		opts []Option
+		exp  string
+	}{
+		{
+			`{"b":1,"a":"x"}
+{"a":"y","c":{"d":[1,2.5]}}
+{"a":"z","e":true}`,
+			[]Option{WithSampleRows(2)},
+			` b | a |       c       
+---+---+---------------
+ 1 | x |  
+   | y | {"d":[1,2.5]} 
+   | z |  
+(3 rows)
+`,
+		},
+		{
+			`[{"a":1},{"b":2.5}]`,
+			nil,
+			` a |  b  
+---+-----
+ 1 |  
+   | 2.5 
+(2 rows)
+`,
+		},
+		{
+			`[["x",1],["y",2,"extra"]]`,
+			[]Option{WithColumnNames("name", "n")},
+			` name | n 
+------+---
+ x    | 1 
+ y    | 2 
+(2 rows)
+`,
+		},
+		{
+			`["x",1]
+["y",2]`,
+			[]Option{WithColumnNames("name", "n")},
+			` name | n 
+------+---
+ x    | 1 
+ y    | 2 
+(2 rows)
+`,
+		},
+		{
+			`[]
+["y",2]`,
+			[]Option{WithColumnNames("name", "n")},
+			` name | n 
+------+---
+      |  
+ y    | 2 
+(2 rows)
+`,
+		},
+		{
+			`[]
+{"a":1}
+{"a":2}`,
+			[]Option{WithColumnNames("a")},
+			` a 
+---
+  
+ 1 
+ 2 
+(3 rows)
+`,
+		},
+		{
+			`[]`,
+			[]Option{WithColumnNames("a")},
+			"(0 rows)\n",
+		},
+	}
+	for i, test := range tests {
+		rs, err := FromJSON([]io.Reader{strings.NewReader(test.s)}, test.opts...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := EncodeTable(buf, rs, WithFormatterOptions(WithJSONConfig("", "", false))); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if actual := buf.String(); actual != test.exp {
+			t.Errorf("test %d expected:\n%q\n---\ngot:\n%q", i, test.exp, actual)
+		}
+	}
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s   string
+		exp error
+	}{
+		{`[[1,2]]`, ErrJSONArrayRowsRequireColumnNames},
+		{`"foo"`, ErrInvalidJSONRow},
+		{``, ErrResultSetHasNoColumns},
+	}
+	for i, test := range tests {
+		if _, err := FromJSON([]io.Reader{strings.NewReader(test.s)}); err != test.exp {
+			t.Errorf("test %d expected error %v, got: %v", i, test.exp, err)
+		}
+	}
+	rs, err := FromJSON([]io.Reader{strings.NewReader(`{"a":1} 2`)}, WithSampleRows(1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for rs.Next() {
+	}
+	if err := rs.Err(); err != ErrInvalidJSONRow {
+		t.Errorf("expected error %v, got: %v", ErrInvalidJSONRow, err)
+	}
+}
+
+func TestFromJSONSampleRows(t *testing.T) {
+	t.Parallel()
+	for _, n := range []int{0, 1} {
+		rs, err := FromJSON([]io.Reader{strings.NewReader(`{"a":1} {"b":2} 3`)}, WithSampleRows(n))
+		if err != nil {
+			t.Fatalf("sample %d expected no error, got: %v", n, err)
+		}
+		if cols, _ := rs.Columns(); len(cols) != 1 || cols[0] != "a" {
+			t.Errorf("sample %d expected columns [a], got: %v", n, cols)
+		}
+	}
+}
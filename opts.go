@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nathan-fiscaletti/consolesize-go"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 // Builder is the shared builder interface.
@@ -24,13 +28,22 @@ type Option interface {
 
 // option wraps setting an option on an encoder.
 type option struct {
-	table     func(*TableEncoder) error
-	expanded  func(*ExpandedEncoder) error
-	json      func(*JSONEncoder) error
-	unaligned func(*UnalignedEncoder) error
-	template  func(*TemplateEncoder) error
-	crosstab  func(*CrosstabView) error
-	err       func(*errEncoder) error
+	table      func(*TableEncoder) error
+	expanded   func(*ExpandedEncoder) error
+	json       func(*JSONEncoder) error
+	unaligned  func(*UnalignedEncoder) error
+	template   func(*TemplateEncoder) error
+	crosstab   func(*CrosstabView) error
+	transpose  func(*TransposeView) error
+	unpivot    func(*UnpivotView) error
+	sort       func(*SortView) error
+	filter     func(*FilterView) error
+	limit      func(*LimitView) error
+	diff       func(*DiffView) error
+	profile    func(*ProfileView) error
+	csvReader  func(*CSVResultSet) error
+	jsonReader func(*JSONResultSet) error
+	err        func(*errEncoder) error
 }
 
 // apply applies the option.
@@ -66,6 +79,51 @@ func (opt option) apply(o any) error {
 			return opt.crosstab(v)
 		}
 		return nil
+	case *TransposeView:
+		if opt.transpose != nil {
+			return opt.transpose(v)
+		}
+		return nil
+	case *UnpivotView:
+		if opt.unpivot != nil {
+			return opt.unpivot(v)
+		}
+		return nil
+	case *SortView:
+		if opt.sort != nil {
+			return opt.sort(v)
+		}
+		return nil
+	case *FilterView:
+		if opt.filter != nil {
+			return opt.filter(v)
+		}
+		return nil
+	case *LimitView:
+		if opt.limit != nil {
+			return opt.limit(v)
+		}
+		return nil
+	case *DiffView:
+		if opt.diff != nil {
+			return opt.diff(v)
+		}
+		return nil
+	case *ProfileView:
+		if opt.profile != nil {
+			return opt.profile(v)
+		}
+		return nil
+	case *CSVResultSet:
+		if opt.csvReader != nil {
+			return opt.csvReader(v)
+		}
+		return nil
+	case *JSONResultSet:
+		if opt.jsonReader != nil {
+			return opt.jsonReader(v)
+		}
+		return nil
 	case *errEncoder:
 		if opt.err != nil {
 			return opt.err(v)
@@ -315,6 +373,18 @@ func WithFormatter(formatter Formatter) Option {
 			view.formatter = formatter
 			return nil
 		},
+		transpose: func(view *TransposeView) error {
+			view.formatter = formatter
+			return nil
+		},
+		diff: func(view *DiffView) error {
+			view.formatter = formatter
+			return nil
+		},
+		profile: func(view *ProfileView) error {
+			view.formatter = formatter
+			return nil
+		},
 	}
 }
 
@@ -507,23 +577,107 @@ func WithWidths(widths ...int) Option {
 	}
 }
 
-// WithSeparator is a encoder option to set the field separator.
+// WithHeaderGroups is a encoder option to set header groups spanning
+// contiguous columns, drawn as an additional header row above the column
+// headers. Groups are specified from left to right, and groups with an empty
+// name (and any columns following the last group) are not grouped.
+//
+// Header groups are not drawn when the header is inline to the top line.
+func WithHeaderGroups(groups ...HeaderGroup) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.headerGroups = groups
+			return nil
+		},
+		template: func(enc *TemplateEncoder) error {
+			enc.headerGroups = groups
+			return nil
+		},
+	}
+}
+
+// WithSeparator is a encoder and csv reader option to set the field
+// separator.
 func WithSeparator(sep rune) Option {
 	return option{
 		unaligned: func(enc *UnalignedEncoder) error {
 			enc.sep = sep
 			return nil
 		},
+		csvReader: func(r *CSVResultSet) error {
+			r.sep = sep
+			return nil
+		},
 	}
 }
 
-// WithQuote is a encoder option to set the field quote.
+// WithQuote is a encoder and csv reader option to set the field quote.
+//
+// For the csv reader, a 0 quote disables quoting.
 func WithQuote(quote rune) Option {
 	return option{
 		unaligned: func(enc *UnalignedEncoder) error {
 			enc.quote = quote
 			return nil
 		},
+		csvReader: func(r *CSVResultSet) error {
+			r.quote = quote
+			return nil
+		},
+	}
+}
+
+// WithHeaderRow is a csv reader option to toggle reading the column names from
+// the first row. When false, columns are named column1, column2, etc.
+func WithHeaderRow(header bool) Option {
+	return option{
+		csvReader: func(r *CSVResultSet) error {
+			r.header = header
+			return nil
+		},
+	}
+}
+
+// WithColumnNames is a csv and json reader option to set the column names.
+//
+// For the csv reader, the names are used in place of the header row. For the
+// json reader, the names are used in place of the discovered columns, and are
+// required when the rows are arrays.
+func WithColumnNames(names ...string) Option {
+	return option{
+		csvReader: func(r *CSVResultSet) error {
+			r.names = names
+			return nil
+		},
+		jsonReader: func(r *JSONResultSet) error {
+			r.names = names
+			return nil
+		},
+	}
+}
+
+// WithSampleRows is a json reader option to set the number of rows used to
+// discover columns, or all rows when n is negative. The first row is always
+// used, including when n is 0. Rows used to discover columns are buffered in
+// memory.
+func WithSampleRows(n int) Option {
+	return option{
+		jsonReader: func(r *JSONResultSet) error {
+			r.sample = n
+			return nil
+		},
+	}
+}
+
+// WithInferTypes is a csv reader option to infer the type (int, float, bool,
+// or time) of each column from the first n rows, or all rows when n is
+// negative. Rows used for inference are buffered in memory.
+func WithInferTypes(n int) Option {
+	return option{
+		csvReader: func(r *CSVResultSet) error {
+			r.infer = n
+			return nil
+		},
 	}
 }
 
@@ -567,6 +721,21 @@ func WithBorder(border int) Option {
 	}
 }
 
+// WithDecimalAlign is a encoder option to align numeric values on their
+// decimal separator.
+func WithDecimalAlign(decimalAlign bool) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.decimalAlign = decimalAlign
+			return nil
+		},
+		expanded: func(enc *ExpandedEncoder) error {
+			enc.decimalAlign = decimalAlign
+			return nil
+		},
+	}
+}
+
 // WithTableAttributes is a encoder option to set the table attributes.
 func WithTableAttributes(a string) Option {
 	return option{
@@ -596,6 +765,8 @@ func WithTemplate(name string) Option {
 				enc.executor = WriteHTMLTo
 			case "asciidoc":
 				enc.executor = WriteAsciidocTo
+			case "latex":
+				enc.executor = WriteLaTeXTo
 			case "vertical":
 				enc.executor = WriteVerticalTo
 			default:
@@ -634,6 +805,18 @@ func WithHeaderTransformer(headerTransformer Transformer) Option {
 			view.headerTransformer = headerTransformer
 			return nil
 		},
+		transpose: func(view *TransposeView) error {
+			view.headerTransformer = headerTransformer
+			return nil
+		},
+		unpivot: func(view *UnpivotView) error {
+			view.headerTransformer = headerTransformer
+			return nil
+		},
+		profile: func(view *ProfileView) error {
+			view.headerTransformer = headerTransformer
+			return nil
+		},
 	}
 }
 
@@ -685,6 +868,26 @@ func WithColumnTypes(columnTypes func(ResultSet, []any, int) error) Option {
 			view.columnTypes = columnTypes
 			return nil
 		},
+		transpose: func(view *TransposeView) error {
+			view.columnTypes = columnTypes
+			return nil
+		},
+		unpivot: func(view *UnpivotView) error {
+			view.columnTypes = columnTypes
+			return nil
+		},
+		profile: func(view *ProfileView) error {
+			view.columnTypes = columnTypes
+			return nil
+		},
+		sort: func(view *SortView) error {
+			view.columnTypes = columnTypes
+			return nil
+		},
+		filter: func(view *FilterView) error {
+			view.columnTypes = columnTypes
+			return nil
+		},
 	}
 }
 
@@ -723,26 +926,404 @@ func WithColumnTypesFunc(f func(*sql.ColumnType) (any, error)) Option {
 }
 
 // WithParams is a view option to set the column parameters.
+//
+// For the crosstab view, the params are the vertical, horizontal, data, and
+// horizontal sort columns, where an empty param uses the default column (see
+// [WithVerticalColumns] and [WithDataColumns] for multiple columns). For the
+// transpose view, the param is the key column.
 func WithParams(params ...string) Option {
 	return option{
 		crosstab: func(view *CrosstabView) error {
 			if len(params) > 4 {
 				return ErrInvalidColumnParams
 			}
-			if len(params) > 0 {
-				view.v = params[0]
+			if len(params) > 0 && params[0] != "" {
+				view.v = []string{params[0]}
 			}
 			if len(params) > 1 {
 				view.h = params[1]
 			}
-			if len(params) > 2 {
-				view.d = params[2]
+			if len(params) > 2 && params[2] != "" {
+				view.d = []string{params[2]}
 			}
 			if len(params) > 3 {
 				view.s = params[3]
 			}
 			return nil
 		},
+		transpose: func(view *TransposeView) error {
+			if len(params) > 1 {
+				return ErrInvalidColumnParams
+			}
+			if len(params) > 0 {
+				view.k = params[0]
+			}
+			return nil
+		},
+	}
+}
+
+// WithVerticalColumns is a view option to set multiple vertical header
+// columns for the crosstab view, overriding the vertical column set by
+// [WithParams]. Rows are keyed by the combined values of the columns.
+func WithVerticalColumns(columns ...string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.v = slices.Clone(columns)
+			return nil
+		},
+	}
+}
+
+// WithDataColumns is a view option to set multiple data columns for the
+// crosstab view, overriding the data column set by [WithParams]. Each
+// horizontal value produces a column for each data column, named using the
+// column pattern (see [WithColumnPattern]).
+func WithDataColumns(columns ...string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.d = slices.Clone(columns)
+			return nil
+		},
+	}
+}
+
+// WithColumnPattern is a view option to set the pattern used to name crosstab
+// columns when there are multiple data columns. The pattern is a fmt format
+// string, passed the horizontal value and the data column name (ie, "%[2]s
+// (%[1]s)"). Defaults to "%s %s". Returns [ErrInvalidColumnPattern] when the
+// pattern's verbs do not match the two values.
+func WithColumnPattern(pattern string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			if strings.Contains(fmt.Sprintf(pattern, "", ""), "%!") {
+				return ErrInvalidColumnPattern
+			}
+			view.pattern = pattern
+			return nil
+		},
+	}
+}
+
+// WithHorizontalSort is a view option to set the sort order of the crosstab
+// horizontal header values. Values are sorted by the horizontal sort column
+// when specified (see [WithParams]), which otherwise are sorted ascending.
+// Numbers, times and bools are sorted by value, and all other values are
+// sorted as strings using the collation (see [WithCollation]).
+func WithHorizontalSort(order SortOrder) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.hsort = order
+			return nil
+		},
+	}
+}
+
+// WithVerticalSort is a view option to set the sort order of the crosstab
+// vertical header values. See [WithHorizontalSort].
+func WithVerticalSort(order SortOrder) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.vsort = order
+			return nil
+		},
+	}
+}
+
+// WithCollation is a view option to set the locale used to collate strings
+// when sorting crosstab header values or sort view rows (ie, "en-US", "sv").
+func WithCollation(locale string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			tag, err := language.Parse(locale)
+			if err != nil {
+				return err
+			}
+			view.collator = collate.New(tag)
+			return nil
+		},
+		sort: func(view *SortView) error {
+			tag, err := language.Parse(locale)
+			if err != nil {
+				return err
+			}
+			view.collator = collate.New(tag)
+			return nil
+		},
+	}
+}
+
+// WithSortKeys is a view option to set the sort view's sort keys.
+func WithSortKeys(keys ...SortKey) Option {
+	return option{
+		sort: func(view *SortView) error {
+			view.keys = keys
+			return nil
+		},
+	}
+}
+
+// WithCountMore is a view option to count the rows remaining after the limit
+// view's limit, reading the rest of the result set. Otherwise, only one row
+// past the limit is read to determine whether more rows remain.
+func WithCountMore(countMore bool) Option {
+	return option{
+		limit: func(view *LimitView) error {
+			view.count = countMore
+			return nil
+		},
+	}
+}
+
+// WithMemoryBudget is a view option to set the approximate number of bytes of
+// rows the sort view buffers in memory before writing sorted runs to temporary
+// files. A budget of 0 or less never writes runs. Defaults to 64 MiB.
+func WithMemoryBudget(budget int64) Option {
+	return option{
+		sort: func(view *SortView) error {
+			view.budget = budget
+			return nil
+		},
+	}
+}
+
+// WithTempDir is a view option to set the directory for the sort view's
+// temporary files. Defaults to [os.TempDir].
+func WithTempDir(dir string) Option {
+	return option{
+		sort: func(view *SortView) error {
+			view.dir = dir
+			return nil
+		},
+	}
+}
+
+// WithIDColumns is a view option to set the id columns for the unpivot view,
+// which are included in each row. Columns can be specified by name or by
+// 1-based position.
+func WithIDColumns(columns ...string) Option {
+	return option{
+		unpivot: func(view *UnpivotView) error {
+			view.ids = columns
+			return nil
+		},
+	}
+}
+
+// WithValueColumns is a view option to set the value columns for the unpivot
+// view, which are each turned into a row. Defaults to all columns that are not
+// id columns. Columns can be specified by name or by 1-based position.
+func WithValueColumns(columns ...string) Option {
+	return option{
+		unpivot: func(view *UnpivotView) error {
+			view.vals = columns
+			return nil
+		},
+	}
+}
+
+// WithVariableColumns is a view option to set the names of the unpivot
+// view's variable and value columns. Defaults to "variable" and "value".
+func WithVariableColumns(variable, value string) Option {
+	return option{
+		unpivot: func(view *UnpivotView) error {
+			view.variable, view.value = variable, value
+			return nil
+		},
+	}
+}
+
+// WithDropNulls is a view option to drop unpivot view rows with NULL values.
+func WithDropNulls(dropNulls bool) Option {
+	return option{
+		unpivot: func(view *UnpivotView) error {
+			view.dropNulls = dropNulls
+			return nil
+		},
+	}
+}
+
+// WithKeyColumns is a view option to set the key columns used to match the
+// rows of the diff view's result sets. Columns can be specified by name or by
+// 1-based position. At least one key column is required.
+func WithKeyColumns(columns ...string) Option {
+	return option{
+		diff: func(view *DiffView) error {
+			view.keys = columns
+			return nil
+		},
+	}
+}
+
+// WithSortedInputs is a view option to indicate the diff view's result sets
+// are sorted ascending by the key columns, allowing the rows to be streamed
+// instead of read into memory.
+func WithSortedInputs(sorted bool) Option {
+	return option{
+		diff: func(view *DiffView) error {
+			view.sorted = sorted
+			return nil
+		},
+	}
+}
+
+// WithDiffLabels is a view option to set the labels of the diff view's result
+// sets, used by unified text diffs. Defaults to "a" and "b".
+func WithDiffLabels(a, b string) Option {
+	return option{
+		diff: func(view *DiffView) error {
+			view.labels = [2]string{a, b}
+			return nil
+		},
+	}
+}
+
+// WithTopK is a view option to set the number of most frequent values listed
+// by the profile view. Defaults to 3.
+func WithTopK(k int) Option {
+	return option{
+		profile: func(view *ProfileView) error {
+			if k < 0 {
+				return ErrInvalidTopK
+			}
+			view.topK = k
+			return nil
+		},
+	}
+}
+
+// WithApproxDistinct is a view option to approximate the profile view's
+// distinct counts and most frequent values, bounding the memory used for
+// columns with many distinct values.
+func WithApproxDistinct(approx bool) Option {
+	return option{
+		profile: func(view *ProfileView) error {
+			view.approx = approx
+			return nil
+		},
+	}
+}
+
+// WithAggregate is a view option to set the aggregate used to combine the
+// values of crosstab cells with more than one value. When not set, the
+// crosstab view fails on cells with more than one value (as does psql).
+func WithAggregate(agg Aggregate) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.agg = agg
+			return nil
+		},
+	}
+}
+
+// WithConcatSeparator is a view option to set the separator used to join
+// values with [AggregateConcat]. Defaults to ", ".
+func WithConcatSeparator(sep string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.sep = sep
+			return nil
+		},
+	}
+}
+
+// WithTotals is a view option to add a total column with the totals of each
+// crosstab row, and a total row with the totals of each crosstab column.
+// Totals use the aggregate set by [WithAggregate], or sum when not set.
+func WithTotals(rows, columns bool) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.rowTotals, view.colTotals = rows, columns
+			return nil
+		},
+	}
+}
+
+// WithTotalLabel is a view option to set the label of the crosstab total
+// column and row (see [WithTotals]). Building the view returns
+// [ErrCrosstabTotalLabelIsAValue] when the label is also a horizontal value
+// (or first vertical value) of the result set. Defaults to "total".
+func WithTotalLabel(label string) Option {
+	return option{
+		crosstab: func(view *CrosstabView) error {
+			view.totalLabel = label
+			return nil
+		},
+	}
+}
+
+// WithGroupBy is a encoder option to group rows by the key column, drawing a
+// divider (or, for expanded output, adding the key to the record header)
+// whenever the key value changes, and suppressing repeated key values. The
+// result set should be sorted by the key column.
+//
+// When subtotal columns are provided, a subtotal row is written after each
+// group, separated from the group's rows by a divider. Columns can be
+// specified by name or by 1-based position.
+func WithGroupBy(column string, subtotals ...string) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.groupBy, enc.subtotals = column, subtotals
+			return nil
+		},
+		expanded: func(enc *ExpandedEncoder) error {
+			enc.groupBy, enc.subtotals = column, subtotals
+			return nil
+		},
+	}
+}
+
+// WithSubtotalLabel is a encoder option to set the value written to the key
+// column of subtotal rows (see [WithGroupBy]), such as a label that cannot be
+// mistaken for a key value. Defaults to "subtotal".
+func WithSubtotalLabel(label string) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.subtotalLabel = label
+			return nil
+		},
+		expanded: func(enc *ExpandedEncoder) error {
+			enc.subtotalLabel = label
+			return nil
+		},
+	}
+}
+
+// WithSuppressRepeated is a encoder option to blank out values in the columns
+// that are equal to the value in the row above. Columns are treated as a
+// hierarchy (ie, parent, child), where a value is only blanked when the values
+// in the preceding columns were also blanked. Columns can be specified by name
+// or by 1-based position.
+//
+// Template encoders provide the row spans of suppressed values to templates
+// (see [Template]), which are written as rowspan cells by the HTML template.
+func WithSuppressRepeated(columns ...string) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.repeated, enc.merge = columns, false
+			return nil
+		},
+		template: func(enc *TemplateEncoder) error {
+			enc.repeated = columns
+			return nil
+		},
+	}
+}
+
+// WithMergeRepeated is a encoder option to suppress repeated values in the
+// columns, similar to [WithSuppressRepeated], and to draw dividers between
+// table rows, leaving out the divider above suppressed values so that they
+// read as cells merged with the cell above.
+func WithMergeRepeated(columns ...string) Option {
+	return option{
+		table: func(enc *TableEncoder) error {
+			enc.repeated, enc.merge = columns, len(columns) != 0
+			return nil
+		},
+		template: func(enc *TemplateEncoder) error {
+			enc.repeated = columns
+			return nil
+		},
 	}
 }
 
@@ -818,13 +1399,7 @@ func withError(err error) Option {
 // resultSetColumns retrieves the columns from a result set and checks the
 // length.
 func resultSetColumns(resultSet ResultSet, n int) ([]*sql.ColumnType, error) {
-	rs, ok := resultSet.(interface {
-		ColumnTypes() ([]*sql.ColumnType, error)
-	})
-	if !ok {
-		return nil, ErrResultSetHasNoColumnTypes
-	}
-	cols, err := rs.ColumnTypes()
+	cols, err := resultSetColumnTypes(resultSet)
 	switch {
 	case err != nil:
 		return nil, err
@@ -834,6 +1409,17 @@ func resultSetColumns(resultSet ResultSet, n int) ([]*sql.ColumnType, error) {
 	return cols, nil
 }
 
+// resultSetColumnTypes retrieves the column types from a result set.
+func resultSetColumnTypes(resultSet ResultSet) ([]*sql.ColumnType, error) {
+	rs, ok := resultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	})
+	if !ok {
+		return nil, ErrResultSetHasNoColumnTypes
+	}
+	return rs.ColumnTypes()
+}
+
 // pagerOpts adds pager options to the table options.
 func pagerOpts(tableOpts []Option, opts map[string]string) []Option {
 	pager, pagerCmd := opts["pager"], opts["pager_cmd"]
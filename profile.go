@@ -0,0 +1,333 @@
+package tblfmt
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ProfileView is a view profiling the columns of a result set, producing a
+// row for each column with the column's name, the Go types of its values, the
+// NULL count, the distinct count, the minimum, maximum and mean values, the
+// most frequent values, and the maximum display width.
+//
+// Distinct counts and the most frequent values are exact, unless approximate
+// counting is enabled (see [WithApproxDistinct]), in which case distinct
+// counts are estimated using HyperLogLog and the most frequent values are
+// tracked using a bounded number of counters.
+//
+// As with the crosstab view, NextResultSet always returns false.
+type ProfileView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// formatter is the formatter.
+	formatter Formatter
+	// headerTransformer is the column header transformer.
+	headerTransformer Transformer
+	// columnTypes is used to build column types for a result set.
+	columnTypes func(ResultSet, []any, int) error
+	// topK is the number of most frequent values.
+	topK int
+	// approx toggles approximate counting.
+	approx bool
+	// profiles are the column profiles.
+	profiles []*columnProfile
+	// pos is the index for the result.
+	pos int
+	// err is the last encountered error.
+	err error
+}
+
+// NewProfileView creates a new profile view.
+func NewProfileView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
+	view := &ProfileView{
+		resultSet: resultSet,
+		formatter: NewEscapeFormatter(),
+		topK:      3,
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// build builds the profile view.
+func (view *ProfileView) build() error {
+	// reset
+	view.pos = -1
+	// get columns
+	clen, cols, err := buildColNames(view.resultSet, view.headerTransformer)
+	if err != nil {
+		return view.fail(err)
+	}
+	view.formatter = formatterFor(view.formatter, view.resultSet, cols)
+	view.profiles = make([]*columnProfile, clen)
+	for i := range clen {
+		view.profiles[i] = newColumnProfile(cols[i], view.approx, view.topK)
+	}
+	// process results
+	for view.resultSet.Next() {
+		r, err := buildColumnTypes(view.resultSet, clen, view.columnTypes)
+		if err != nil {
+			return view.fail(err)
+		}
+		if err := view.resultSet.Scan(r...); err != nil {
+			return view.fail(err)
+		}
+		vals, err := view.formatter.Format(r)
+		if err != nil {
+			return view.fail(err)
+		}
+		for i, p := range view.profiles {
+			p.add(deref(r[i]), vals[i])
+		}
+	}
+	if err := view.resultSet.Err(); err != nil {
+		return view.fail(err)
+	}
+	return nil
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *ProfileView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// Next satisfies the ResultSet interface.
+func (view *ProfileView) Next() bool {
+	if view.err != nil {
+		return false
+	}
+	view.pos++
+	return view.pos < len(view.profiles)
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *ProfileView) Scan(v ...any) error {
+	if view.pos < 0 || len(view.profiles) <= view.pos {
+		return sql.ErrNoRows
+	}
+	row := view.profiles[view.pos].row()
+	if len(v) != len(row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(row), len(v))
+	}
+	for i := range row {
+		if err := assign(v[i], row[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *ProfileView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	return []string{"column", "type", "nulls", "distinct", "min", "max", "mean", "top", "width"}, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (view *ProfileView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *ProfileView) Err() error {
+	return view.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *ProfileView) NextResultSet() bool {
+	return false
+}
+
+// columnProfile is the profile of a column.
+type columnProfile struct {
+	// name is the column name.
+	name string
+	// types are the Go types of the values.
+	types []string
+	// nulls is the NULL count.
+	nulls int64
+	// sum is the sum of numeric values.
+	sum subtotal
+	// nums is the count of numeric values.
+	nums int64
+	// min and max are the minimum and maximum values.
+	min, max any
+	// topK is the number of most frequent values.
+	topK int
+	// counts are the counts of each value, or of the tracked values when
+	// approximating.
+	counts map[string]int64
+	// order is the order the counted values were first seen.
+	order map[string]int
+	// hll is the distinct count estimator, when approximating.
+	hll *hyperLogLog
+	// width is the maximum display width.
+	width int
+}
+
+// newColumnProfile creates a new column profile.
+func newColumnProfile(name string, approx bool, topK int) *columnProfile {
+	p := &columnProfile{
+		name:   name,
+		topK:   topK,
+		counts: make(map[string]int64),
+		order:  make(map[string]int),
+	}
+	if approx {
+		p.hll = newHyperLogLog()
+	}
+	return p
+}
+
+// add adds a value and its formatted value to the profile.
+func (p *columnProfile) add(v any, val *Value) {
+	if val != nil {
+		p.width = max(p.width, val.MaxWidth(0, 8))
+	}
+	if unwrapValue(v) == nil {
+		p.nulls++
+		return
+	}
+	if typ := fmt.Sprintf("%T", v); !slices.Contains(p.types, typ) {
+		p.types = append(p.types, typ)
+	}
+	if z := toNumber(v); z != nil {
+		p.sum.add(z)
+		p.nums++
+	}
+	if p.min == nil || compareValues(v, p.min, nil) < 0 {
+		p.min = v
+	}
+	if p.max == nil || compareValues(v, p.max, nil) > 0 {
+		p.max = v
+	}
+	key := toString(unwrapValue(v))
+	if _, ok := p.order[key]; !ok {
+		p.order[key] = len(p.order)
+	}
+	if p.hll == nil {
+		p.counts[key]++
+		return
+	}
+	// approximate the most frequent values using the space-saving algorithm
+	p.hll.add(key)
+	switch n := max(64, 10*p.topK); {
+	case p.counts[key] != 0 || len(p.counts) < n:
+		p.counts[key]++
+	default:
+		var minKey string
+		minCount := int64(math.MaxInt64)
+		for k, c := range p.counts {
+			if c < minCount || c == minCount && k < minKey {
+				minKey, minCount = k, c
+			}
+		}
+		delete(p.counts, minKey)
+		p.counts[key] = minCount + 1
+	}
+	if len(p.order) > 2*len(p.counts) {
+		for k := range p.order {
+			if _, ok := p.counts[k]; !ok {
+				delete(p.order, k)
+			}
+		}
+	}
+}
+
+// row returns the profile row.
+func (p *columnProfile) row() []any {
+	distinct := int64(len(p.counts))
+	if p.hll != nil {
+		distinct = p.hll.count()
+	}
+	var mean any
+	if p.nums != 0 {
+		mean = p.sum.f / float64(p.nums)
+	}
+	// most frequent values
+	keys := make([]string, 0, len(p.counts))
+	for k := range p.counts {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if c := cmp.Compare(p.counts[b], p.counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(p.order[a], p.order[b])
+	})
+	top := make([]string, 0, p.topK)
+	for _, k := range keys[:min(p.topK, len(keys))] {
+		top = append(top, k+" ("+strconv.FormatInt(p.counts[k], 10)+")")
+	}
+	return []any{
+		p.name,
+		strings.Join(p.types, ", "),
+		p.nulls,
+		distinct,
+		p.min,
+		p.max,
+		mean,
+		strings.Join(top, ", "),
+		int64(p.width),
+	}
+}
+
+// hllPrecision is the HyperLogLog precision.
+const hllPrecision = 14
+
+// hyperLogLog is a HyperLogLog distinct count estimator.
+type hyperLogLog struct {
+	seed maphash.Seed
+	regs []uint8
+}
+
+// newHyperLogLog creates a new HyperLogLog distinct count estimator.
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{
+		seed: maphash.MakeSeed(),
+		regs: make([]uint8, 1<<hllPrecision),
+	}
+}
+
+// add adds s to the estimator.
+func (h *hyperLogLog) add(s string) {
+	x := maphash.String(h.seed, s)
+	i := x >> (64 - hllPrecision)
+	r := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1))) + 1
+	h.regs[i] = max(h.regs[i], r)
+}
+
+// count returns the estimated distinct count.
+func (h *hyperLogLog) count() int64 {
+	m := float64(len(h.regs))
+	var sum float64
+	var zeros int
+	for _, r := range h.regs {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// small range correction
+	if e <= 2.5*m && zeros != 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(e))
+}
@@ -0,0 +1,122 @@
+package tblfmt
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"github.com/xo/tblfmt/internal"
+)
+
+func TestNewProfileView(t *testing.T) {
+	t.Parallel()
+	rs := internal.New([]string{"id", "name", "qty"}, [][]any{
+		{1, "a", 1.5},
+		{2, "bb", nil},
+		{3, "a", 3},
+		{4, "ccc\nd", nil},
+		{5, "a", 4.5},
+	})
+	view, err := NewProfileView(rs, WithTopK(2))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := ` column |     type     | nulls | distinct | min | max | mean |      top       | width 
+--------+--------------+-------+----------+-----+-----+------+----------------+-------
+ id     | int          |     0 |        5 |   1 |   5 |    3 | 1 (1), 2 (1)   |     1 
+ name   | string       |     0 |        3 | a   | ccc+|      | a (3), bb (1)  |     3 
+        |              |       |          |     | d   |      |                |  
+ qty    | float64, int |     2 |        3 | 1.5 | 4.5 |    3 | 1.5 (1), 3 (1) |     3 
+(3 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, view); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%s\n---\ngot:\n%s", exp, actual)
+	}
+}
+
+func TestProfileViewApprox(t *testing.T) {
+	t.Parallel()
+	var vals [][]any
+	for i := range 20000 {
+		vals = append(vals, []any{i % 5000, i % 3})
+	}
+	view, err := NewProfileView(internal.New([]string{"a", "b"}, vals), WithApproxDistinct(true), WithTopK(1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var n int
+	for view.Next() {
+		row := make([]any, 9)
+		for i := range row {
+			row[i] = new(any)
+		}
+		if err := view.Scan(row...); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		distinct, exp := (*row[3].(*any)).(int64), []int64{5000, 3}[n]
+		if d := float64(distinct-exp) / float64(exp); d < -0.03 || d > 0.03 {
+			t.Errorf("row %d expected distinct approximately %d, got: %d", n, exp, distinct)
+		}
+		if n == 1 {
+			if top := (*row[7].(*any)).(string); top != "0 (6667)" {
+				t.Errorf("expected top %q, got: %q", "0 (6667)", top)
+			}
+		}
+		n++
+	}
+}
+
+func TestProfileViewInvalidTopK(t *testing.T) {
+	t.Parallel()
+	_, err := NewProfileView(internal.New([]string{"a"}, [][]any{{1}}), WithTopK(-1))
+	if err != ErrInvalidTopK {
+		t.Errorf("expected error %v, got: %v", ErrInvalidTopK, err)
+	}
+}
+
+func TestProfileViewScan(t *testing.T) {
+	t.Parallel()
+	for _, vals := range [][]any{
+		{"b", 10, "a", 2, "10"},
+		{2, "10", "a", 10, "b"},
+		{"a", "b", 10, "10", 2},
+	} {
+		var rows [][]any
+		for _, val := range vals {
+			rows = append(rows, []any{val})
+		}
+		view, err := NewProfileView(internal.New([]string{"a"}, rows))
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		row := make([]any, 9)
+		for i := range row {
+			row[i] = new(any)
+		}
+		if err := view.Scan(row...); err != sql.ErrNoRows {
+			t.Errorf("expected error %v, got: %v", sql.ErrNoRows, err)
+		}
+		if !view.Next() {
+			t.Fatalf("expected a row")
+		}
+		if err := view.Scan(row[:4]...); err == nil {
+			t.Errorf("expected an error scanning into %d destination arguments", 4)
+		}
+		if err := view.Scan(row...); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if min, max := *row[4].(*any), *row[5].(*any); min != 2 || max != "b" {
+			t.Errorf("%v expected min 2 and max %q, got: %v and %v", vals, "b", min, max)
+		}
+		if view.Next() {
+			t.Fatalf("expected no more rows")
+		}
+		if err := view.Scan(row...); err != sql.ErrNoRows {
+			t.Errorf("expected error %v, got: %v", sql.ErrNoRows, err)
+		}
+	}
+}
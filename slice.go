@@ -0,0 +1,409 @@
+package tblfmt
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SliceResultSet is a result set for a Go slice of structs or maps.
+type SliceResultSet struct {
+	// v is the slice value.
+	v reflect.Value
+	// cols are the columns.
+	cols []sliceColumn
+	// pos is the current row position.
+	pos int
+	// types are the column types.
+	types []*sql.ColumnType
+}
+
+// FromSlice creates a result set for a Go slice (or array, or pointer to
+// either) of structs, pointers to structs, or maps with string keys.
+//
+// For structs, columns are the exported fields, with the fields of embedded
+// structs flattened in place. A field's column can be configured with a
+// `tblfmt` tag, as in the following:
+//
+//	type Row struct {
+//		ID    int     `tblfmt:"id,align=right"`
+//		Name  string  `tblfmt:"name"`
+//		Email *string `tblfmt:",omitempty"`
+//		Code  string  `tblfmt:"-"`
+//	}
+//
+// The first tag value is the column name (defaulting to the field name). A
+// column with the omitempty option is removed when the field is the zero value
+// for every row, and a column with the align option (left, right, or center)
+// is scanned as an [Aligned] value. A "-" tag skips the field.
+//
+// For maps, columns are the union of the keys of each map, in the order first
+// seen, with the keys of each map visited in sorted order.
+//
+// Pointer values are dereferenced, and nil pointers are scanned as nil.
+func FromSlice(v any) (*SliceResultSet, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, ErrValueIsNotASlice
+	}
+	typ := rv.Type().Elem()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	var cols []sliceColumn
+	switch {
+	case typ.Kind() == reflect.Struct:
+		cols = structColumns(typ)
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String,
+		typ.Kind() == reflect.Interface:
+		var err error
+		if cols, err = mapColumns(rv); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrSliceHasInvalidElementType
+	}
+	// remove empty columns
+	cols = slices.DeleteFunc(cols, func(col sliceColumn) bool {
+		if !col.omitempty {
+			return false
+		}
+		for i := range rv.Len() {
+			if v, ok := col.value(rv.Index(i)); ok && !v.IsZero() {
+				return false
+			}
+		}
+		return true
+	})
+	return &SliceResultSet{
+		v:    rv,
+		cols: cols,
+		pos:  -1,
+	}, nil
+}
+
+// Next satisfies the ResultSet interface.
+func (r *SliceResultSet) Next() bool {
+	if r.pos < r.v.Len() {
+		r.pos++
+	}
+	return r.pos < r.v.Len()
+}
+
+// Scan satisfies the ResultSet interface.
+//
+// Values are assigned to *any destinations as-is (wrapped as an [Aligned]
+// value when the column has an alignment), otherwise the value is assigned or
+// converted to the destination's type.
+func (r *SliceResultSet) Scan(dest ...any) error {
+	if r.pos < 0 || r.v.Len() <= r.pos {
+		return sql.ErrNoRows
+	}
+	if len(dest) != len(r.cols) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.cols), len(dest))
+	}
+	row := r.v.Index(r.pos)
+	for i, col := range r.cols {
+		var val any
+		if v, ok := col.value(row); ok {
+			val = v.Interface()
+		}
+		if err := assign(dest[i], val, col.align); err != nil {
+			return fmt.Errorf("column %d (%s): %w", i, col.name, err)
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (r *SliceResultSet) Columns() ([]string, error) {
+	cols := make([]string, len(r.cols))
+	for i, col := range r.cols {
+		cols[i] = col.name
+	}
+	return cols, nil
+}
+
+// ColumnTypes returns the column types, with the scan type of each column
+// being the field's type, or an empty interface type for map values, nullable
+// (pointer) fields, and fields with an alignment.
+func (r *SliceResultSet) ColumnTypes() ([]*sql.ColumnType, error) {
+	if r.types == nil {
+		cols := make([]columnType, len(r.cols))
+		for i, col := range r.cols {
+			cols[i] = columnType{
+				name:     col.name,
+				typ:      col.typ,
+				nullable: col.typ.Kind() == reflect.Interface,
+			}
+		}
+		var err error
+		if r.types, err = newColumnTypes(cols); err != nil {
+			return nil, err
+		}
+	}
+	return r.types, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (r *SliceResultSet) Close() error {
+	r.pos = r.v.Len()
+	return nil
+}
+
+// Err satisfies the ResultSet interface.
+func (r *SliceResultSet) Err() error {
+	return nil
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (r *SliceResultSet) NextResultSet() bool {
+	return false
+}
+
+// sliceColumn is a column of a slice result set.
+type sliceColumn struct {
+	// name is the column name.
+	name string
+	// index is the struct field index.
+	index []int
+	// key is the map key.
+	key string
+	// typ is the scan type.
+	typ reflect.Type
+	// align is the alignment.
+	align Align
+	// omitempty removes the column when empty.
+	omitempty bool
+}
+
+// value returns the column's value for the row, returning false when the
+// value is nil.
+func (col sliceColumn) value(v reflect.Value) (reflect.Value, bool) {
+	if v = indirect(v); !v.IsValid() {
+		return v, false
+	}
+	if col.index == nil {
+		if v.Kind() != reflect.Map {
+			return reflect.Value{}, false
+		}
+		v = v.MapIndex(reflect.ValueOf(col.key).Convert(v.Type().Key()))
+	} else {
+		for _, i := range col.index {
+			if v = indirect(v); !v.IsValid() {
+				return v, false
+			}
+			v = v.Field(i)
+		}
+	}
+	v = indirect(v)
+	return v, v.IsValid()
+}
+
+// structColumns returns the columns for the exported fields of a struct type.
+//
+// Fields of embedded structs are promoted using the same rules as
+// encoding/json: the shallowest field with a name wins, a tagged field wins
+// over untagged fields at the same depth, and any remaining conflicting fields
+// are dropped.
+func structColumns(typ reflect.Type) []sliceColumn {
+	fields := structFields(typ, nil, map[reflect.Type]bool{typ: true})
+	names := make(map[string][]int)
+	for i, f := range fields {
+		names[f.name] = append(names[f.name], i)
+	}
+	var cols []sliceColumn
+	for i, f := range fields {
+		if dominantField(fields, names[f.name]) == i {
+			cols = append(cols, f.sliceColumn)
+		}
+	}
+	return cols
+}
+
+// dominantField returns the index of the dominant field of the fields sharing
+// a name, or -1 when no field is dominant.
+func dominantField(fields []structField, indexes []int) int {
+	depth := len(fields[indexes[0]].index)
+	for _, i := range indexes {
+		depth = min(depth, len(fields[i].index))
+	}
+	dominant, tagged := -1, -1
+	var n, m int
+	for _, i := range indexes {
+		if len(fields[i].index) != depth {
+			continue
+		}
+		dominant, n = i, n+1
+		if fields[i].tagged {
+			tagged, m = i, m+1
+		}
+	}
+	switch {
+	case n == 1:
+		return dominant
+	case m == 1:
+		return tagged
+	}
+	return -1
+}
+
+// structField is a candidate column for a struct field.
+type structField struct {
+	sliceColumn
+	// tagged is whether the column was named by a tag.
+	tagged bool
+}
+
+// structFields returns the candidate columns for the fields of a struct type,
+// including the fields promoted from embedded structs, in index order. Seen
+// contains the struct types being expanded, preventing infinite recursion on
+// recursively embedded types.
+func structFields(typ reflect.Type, index []int, seen map[reflect.Type]bool) []structField {
+	var fields []structField
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		tag := f.Tag.Get("tblfmt")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		idx := append(slices.Clone(index), i)
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// exported fields of unexported embedded structs are promoted
+			if !seen[ft] {
+				seen[ft] = true
+				fields = append(fields, structFields(ft, idx, seen)...)
+				delete(seen, ft)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		col := structField{
+			sliceColumn: sliceColumn{
+				name:  name,
+				index: idx,
+				typ:   f.Type,
+				align: -1,
+			},
+			tagged: name != "",
+		}
+		if name == "" {
+			col.name = f.Name
+		}
+		for opt := range strings.SplitSeq(opts, ",") {
+			switch k, v, _ := strings.Cut(opt, "="); k {
+			case "omitempty":
+				col.omitempty = true
+			case "align":
+				switch v {
+				case "left":
+					col.align = AlignLeft
+				case "right":
+					col.align = AlignRight
+				case "center":
+					col.align = AlignCenter
+				}
+			}
+		}
+		if col.typ.Kind() == reflect.Pointer || col.align != -1 {
+			col.typ = reflect.TypeFor[any]()
+		}
+		fields = append(fields, col)
+	}
+	return fields
+}
+
+// mapColumns returns the columns for the keys of a slice of maps.
+func mapColumns(v reflect.Value) ([]sliceColumn, error) {
+	var cols []sliceColumn
+	seen := make(map[string]bool)
+	for i := range v.Len() {
+		m := indirect(v.Index(i))
+		switch {
+		case !m.IsValid():
+			continue
+		case m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String:
+			return nil, ErrSliceHasInvalidElementType
+		}
+		keys := make([]string, 0, m.Len())
+		for _, k := range m.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if !seen[key] {
+				cols = append(cols, sliceColumn{
+					name:  key,
+					key:   key,
+					typ:   reflect.TypeFor[any](),
+					align: -1,
+				})
+				seen[key] = true
+			}
+		}
+	}
+	return cols, nil
+}
+
+// indirect dereferences pointer and interface values, returning an invalid
+// value when nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// assign assigns a value to a scan destination.
+func assign(dest, val any, align Align) error {
+	switch d := dest.(type) {
+	case *any:
+		if val != nil && align != -1 {
+			val = Aligned{Value: val, Align: align}
+		}
+		*d = val
+		return nil
+	case sql.Scanner:
+		if z, ok := val.(driver.Valuer); ok {
+			var err error
+			if val, err = z.Value(); err != nil {
+				return err
+			}
+		}
+		return d.Scan(val)
+	}
+	d := reflect.ValueOf(dest)
+	if d.Kind() != reflect.Pointer || d.IsNil() {
+		return fmt.Errorf("destination not a pointer: %T", dest)
+	}
+	d = d.Elem()
+	if val == nil {
+		d.SetZero()
+		return nil
+	}
+	switch v := reflect.ValueOf(val); {
+	case v.Type().AssignableTo(d.Type()):
+		d.Set(v)
+	case v.Type().ConvertibleTo(d.Type()):
+		d.Set(v.Convert(d.Type()))
+	default:
+		return fmt.Errorf("unsupported scan, storing %T into type %T", val, dest)
+	}
+	return nil
+}
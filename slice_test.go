@@ -0,0 +1,131 @@
+package tblfmt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFromSlice(t *testing.T) {
+	t.Parallel()
+	type Base struct {
+		ID int `tblfmt:"id"`
+	}
+	type Row struct {
+		Base
+		Name    string
+		Code    string  `tblfmt:"code,align=right"`
+		Email   *string `tblfmt:"email"`
+		Unused  string  `tblfmt:",omitempty"`
+		Skipped string  `tblfmt:"-"`
+		private string
+	}
+	email := "a@example.com"
+	rows := []*Row{
+		{Base{1}, "alice", "a", &email, "", "x", "y"},
+		nil,
+		{Base{2}, "bob", "bb", nil, "", "x", "y"},
+	}
+	exp := ` id | Name  | code |     email     
+----+-------+------+---------------
+  1 | alice |    a | a@example.com 
+    |       |      |  
+  2 | bob   |   bb |  
+(3 rows)
+`
+	for _, useColumnTypes := range []bool{false, true} {
+		if useColumnTypes {
+			// nil rows scan as zero values to non-nullable column types
+			rows[1] = &Row{}
+			exp = strings.Replace(exp, "    |       |      |  \n", "  0 |       |      |  \n", 1)
+		}
+		rs, err := FromSlice(rows)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		buf := new(bytes.Buffer)
+		if err := EncodeTable(buf, rs, WithUseColumnTypes(useColumnTypes)); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if actual := buf.String(); actual != exp {
+			t.Errorf("use column types %t expected:\n%q\n---\ngot:\n%q", useColumnTypes, exp, actual)
+		}
+	}
+}
+
+func TestFromSliceEmbedded(t *testing.T) {
+	t.Parallel()
+	type inner struct {
+		Code   string
+		Hidden int `tblfmt:"-"`
+	}
+	type A struct {
+		Name string
+		X    int
+	}
+	type B struct {
+		Name string
+		X    int `tblfmt:"X"`
+		Y    int
+	}
+	type C struct {
+		Y int
+	}
+	type Row struct {
+		inner
+		A
+		B
+		*C
+		ID   int
+		Name string
+	}
+	type Node struct {
+		*Node
+		V int
+	}
+	tests := []struct {
+		v   any
+		exp string
+	}{
+		{[]Row{{inner{"c", 1}, A{"a", 1}, B{"b", 2, 3}, &C{4}, 5, "n"}}, `[{"Code":"c","X":2,"ID":5,"Name":"n"}]`},
+		{[]Node{{&Node{nil, 1}, 2}}, `[{"V":2}]`},
+	}
+	for i, test := range tests {
+		rs, err := FromSlice(test.v)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := EncodeJSON(buf, rs); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if actual := buf.String(); actual != test.exp {
+			t.Errorf("test %d expected:\n%q\n---\ngot:\n%q", i, test.exp, actual)
+		}
+	}
+}
+
+func TestFromSliceMaps(t *testing.T) {
+	t.Parallel()
+	rs, err := FromSlice([]map[string]any{
+		{"b": 1, "a": "x"},
+		{"c": true, "a": "y"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `[{"a":"x","b":1,"c":null},{"a":"y","b":null,"c":true}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, rs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+	if _, err := FromSlice(42); err != ErrValueIsNotASlice {
+		t.Errorf("expected error %v, got: %v", ErrValueIsNotASlice, err)
+	}
+	if _, err := FromSlice([]int{1}); err != ErrSliceHasInvalidElementType {
+		t.Errorf("expected error %v, got: %v", ErrSliceHasInvalidElementType, err)
+	}
+}
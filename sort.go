@@ -0,0 +1,423 @@
+package tblfmt
+
+import (
+	"bufio"
+	"container/heap"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+func init() {
+	gob.Register(time.Time{})
+}
+
+// NullsOrder is the position of NULL values in a sort.
+type NullsOrder int
+
+// Nulls orders.
+const (
+	// NullsDefault sorts NULL values last when ascending, and first when
+	// descending (as does PostgreSQL).
+	NullsDefault NullsOrder = iota
+	// NullsFirst sorts NULL values first.
+	NullsFirst
+	// NullsLast sorts NULL values last.
+	NullsLast
+)
+
+// SortKey is a sort view key.
+type SortKey struct {
+	// Column is the column name or 1-based position.
+	Column string
+	// Order is the sort order. Defaults to ascending.
+	Order SortOrder
+	// Nulls is the position of NULL values.
+	Nulls NullsOrder
+}
+
+// compare compares a and b, using the collator for strings.
+func (key SortKey) compare(a, b any, c *collate.Collator) int {
+	a, b = unwrapValue(a), unwrapValue(b)
+	n := 1
+	if key.Nulls == NullsFirst || key.Nulls == NullsDefault && key.Order == SortDesc {
+		n = -1
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return n
+	case b == nil:
+		return -n
+	}
+	return key.Order.apply(compareValues(a, b, c))
+}
+
+// SortView is a view that sorts the rows of a result set by one or more
+// columns.
+//
+// Rows are buffered in memory until the memory budget is exceeded (see
+// [WithMemoryBudget]), after which the sorted rows are written as a run to a
+// temporary file. Runs are merged when the rows are read. Values are
+// converted to driver values (see [driver.Value]) when buffered. Values that
+// cannot be converted are kept as is, and must be registered with
+// [gob.Register] to be written to a temporary file.
+type SortView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// columnTypes is used to build column types for a result set.
+	columnTypes func(ResultSet, []any, int) error
+	// keys are the sort keys.
+	keys []SortKey
+	// budget is the memory budget in bytes.
+	budget int64
+	// dir is the directory for temporary files.
+	dir string
+	// collator is the collator used to sort strings.
+	collator *collate.Collator
+	// indexes are the sort key column indexes.
+	indexes []int
+	// rows are the buffered rows.
+	rows [][]any
+	// pos is the index of the current buffered row.
+	pos int
+	// runs are the runs being merged.
+	runs sortRuns
+	// row is the current row.
+	row []any
+	// err is the last encountered error.
+	err error
+}
+
+// NewSortView creates a new sort view. Use [WithSortKeys] to specify the sort
+// keys.
+func NewSortView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
+	view := &SortView{
+		resultSet: resultSet,
+		budget:    64 << 20,
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	if view.collator == nil {
+		view.collator = collate.New(language.Und)
+	}
+	if err := view.build(); err != nil {
+		view.cleanup()
+		return nil, err
+	}
+	return view, nil
+}
+
+// build reads and sorts the rows of the current result set.
+func (view *SortView) build() error {
+	// reset
+	view.pos, view.rows, view.row = -1, nil, nil
+	// get columns
+	clen, cols, err := buildColNames(view.resultSet, nil)
+	if err != nil {
+		return view.fail(err)
+	}
+	view.indexes = view.indexes[:0]
+	for _, key := range view.keys {
+		i := indexOf(cols, key.Column)
+		if i == -1 {
+			return view.fail(ErrSortColumnNotInResult)
+		}
+		view.indexes = append(view.indexes, i)
+	}
+	// process results
+	var size int64
+	for view.resultSet.Next() {
+		r, err := buildColumnTypes(view.resultSet, clen, view.columnTypes)
+		if err != nil {
+			return view.fail(err)
+		}
+		if err := view.resultSet.Scan(r...); err != nil {
+			return view.fail(err)
+		}
+		row := make([]any, clen)
+		for i := range r {
+			row[i] = driverValue(r[i])
+		}
+		view.rows, size = append(view.rows, row), size+rowSize(row)
+		if view.budget > 0 && size > view.budget {
+			if err := view.spill(); err != nil {
+				return view.fail(err)
+			}
+			size = 0
+		}
+	}
+	if err := view.resultSet.Err(); err != nil {
+		return view.fail(err)
+	}
+	if len(view.runs) == 0 {
+		slices.SortStableFunc(view.rows, view.compare)
+		return nil
+	}
+	if len(view.rows) != 0 {
+		if err := view.spill(); err != nil {
+			return view.fail(err)
+		}
+	}
+	// read the first row of each run
+	runs := view.runs
+	view.runs = nil
+	for _, run := range runs {
+		switch err := run.next(); {
+		case errors.Is(err, io.EOF):
+			run.close()
+		case err != nil:
+			view.runs = runs
+			return view.fail(err)
+		default:
+			view.runs = append(view.runs, run)
+		}
+	}
+	heap.Init(&view.runs)
+	return nil
+}
+
+// spill sorts the buffered rows and writes them as a run to a temporary file.
+func (view *SortView) spill() error {
+	slices.SortStableFunc(view.rows, view.compare)
+	f, err := os.CreateTemp(view.dir, "tblfmt-sort-*")
+	if err != nil {
+		return err
+	}
+	run := &sortRun{
+		f:       f,
+		i:       len(view.runs),
+		compare: view.compare,
+	}
+	view.runs = append(view.runs, run)
+	w := bufio.NewWriter(f)
+	enc := gob.NewEncoder(w)
+	for _, row := range view.rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	run.dec = gob.NewDecoder(bufio.NewReader(f))
+	view.rows = nil
+	return nil
+}
+
+// compare compares rows a and b by the sort keys.
+func (view *SortView) compare(a, b []any) int {
+	for i, j := range view.indexes {
+		if c := view.keys[i].compare(a[j], b[j], view.collator); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *SortView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// cleanup closes and removes the temporary files of any remaining runs.
+func (view *SortView) cleanup() {
+	for _, run := range view.runs {
+		run.close()
+	}
+	view.runs = nil
+}
+
+// Next satisfies the ResultSet interface.
+func (view *SortView) Next() bool {
+	if view.err != nil {
+		return false
+	}
+	if view.runs == nil {
+		view.pos++
+		if view.pos < len(view.rows) {
+			view.row = view.rows[view.pos]
+			return true
+		}
+		view.row = nil
+		return false
+	}
+	if len(view.runs) == 0 {
+		view.row = nil
+		return false
+	}
+	run := view.runs[0]
+	view.row = run.row
+	switch err := run.next(); {
+	case errors.Is(err, io.EOF):
+		heap.Pop(&view.runs)
+		run.close()
+	case err != nil:
+		view.fail(err)
+		return false
+	default:
+		heap.Fix(&view.runs, 0)
+	}
+	return true
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *SortView) Scan(v ...any) error {
+	if view.row == nil {
+		return sql.ErrNoRows
+	}
+	for i := 0; i < len(view.row) && i < len(v); i++ {
+		if err := assign(v[i], view.row[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *SortView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	return view.resultSet.Columns()
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *SortView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return resultSetColumnTypes(view.resultSet)
+}
+
+// Close satisfies the ResultSet interface.
+func (view *SortView) Close() error {
+	view.cleanup()
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *SortView) Err() error {
+	return view.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *SortView) NextResultSet() bool {
+	view.cleanup()
+	if view.err != nil || !view.resultSet.NextResultSet() {
+		return false
+	}
+	if err := view.build(); err != nil {
+		view.cleanup()
+	}
+	return true
+}
+
+// sortRun is a run of sorted rows written to a temporary file.
+type sortRun struct {
+	f       *os.File
+	dec     *gob.Decoder
+	i       int
+	row     []any
+	compare func(a, b []any) int
+}
+
+// next reads the next row of the run.
+func (run *sortRun) next() error {
+	var row []any
+	if err := run.dec.Decode(&row); err != nil {
+		return err
+	}
+	run.row = row
+	return nil
+}
+
+// close closes and removes the run's temporary file.
+func (run *sortRun) close() {
+	_ = run.f.Close()
+	_ = os.Remove(run.f.Name())
+}
+
+// sortRuns is a heap of runs, ordered by their current rows.
+type sortRuns []*sortRun
+
+// Len satisfies the heap.Interface interface.
+func (runs sortRuns) Len() int {
+	return len(runs)
+}
+
+// Less satisfies the heap.Interface interface. Runs with equal rows are
+// ordered by their position, keeping the sort stable.
+func (runs sortRuns) Less(i, j int) bool {
+	if c := runs[i].compare(runs[i].row, runs[j].row); c != 0 {
+		return c < 0
+	}
+	return runs[i].i < runs[j].i
+}
+
+// Swap satisfies the heap.Interface interface.
+func (runs sortRuns) Swap(i, j int) {
+	runs[i], runs[j] = runs[j], runs[i]
+}
+
+// Push satisfies the heap.Interface interface.
+func (runs *sortRuns) Push(v any) {
+	*runs = append(*runs, v.(*sortRun))
+}
+
+// Pop satisfies the heap.Interface interface.
+func (runs *sortRuns) Pop() any {
+	old := *runs
+	run := old[len(old)-1]
+	*runs = old[:len(old)-1]
+	return run
+}
+
+// driverValue dereferences v and converts it to a driver value, returning v
+// as is when it cannot be converted.
+func driverValue(v any) any {
+	v = deref(v)
+	if z, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		return z
+	}
+	return v
+}
+
+// rowSize returns the approximate size in memory of a buffered row, including
+// the row's slice header and an interface value for each column.
+func rowSize(row []any) int64 {
+	size := 24 + 16*int64(len(row))
+	for _, v := range row {
+		size += valueSize(v)
+	}
+	return size
+}
+
+// valueSize returns the approximate size in memory of the value boxed in an
+// interface value.
+func valueSize(v any) int64 {
+	switch z := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return 16 + int64(len(z))
+	case []byte:
+		return 24 + int64(len(z))
+	case time.Time:
+		return 24
+	}
+	return 8
+}
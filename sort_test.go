@@ -0,0 +1,94 @@
+package tblfmt
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xo/tblfmt/internal"
+)
+
+func TestNewSortView(t *testing.T) {
+	t.Parallel()
+	t1 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	rs := func() ResultSet {
+		return internal.New([]string{"g", "n", "s", "t"}, [][]any{
+			{"b", 1, "x", t2},
+			{"a", nil, "Ä", t1},
+			{"b", 10, "y", nil},
+			{"a", 2, "z", t2},
+			{"b", nil, "x", t1},
+			{"a", 2, "b", nil},
+			{"c", 9, nil, t1},
+		})
+	}
+	tests := []struct {
+		keys []SortKey
+		exp  string
+	}{
+		{[]SortKey{{Column: "g"}, {Column: "n", Order: SortDesc, Nulls: NullsLast}}, `a,2,z,2024-01-02T01:00:00Z
+a,2,b,
+a,,Ä,2024-01-02T00:00:00Z
+b,10,y,
+b,1,x,2024-01-02T01:00:00Z
+b,,x,2024-01-02T00:00:00Z
+c,9,,2024-01-02T00:00:00Z`},
+		{[]SortKey{{Column: "3", Nulls: NullsFirst}, {Column: "t", Order: SortDesc}}, `c,9,,2024-01-02T00:00:00Z
+a,,Ä,2024-01-02T00:00:00Z
+a,2,b,
+b,1,x,2024-01-02T01:00:00Z
+b,,x,2024-01-02T00:00:00Z
+b,10,y,
+a,2,z,2024-01-02T01:00:00Z`},
+	}
+	for i, test := range tests {
+		for _, budget := range []int64{0, 1, 100} {
+			dir := t.TempDir()
+			view, err := NewSortView(rs(), WithSortKeys(test.keys...), WithMemoryBudget(budget), WithTempDir(dir))
+			if err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, budget, err)
+			}
+			if budget != 0 {
+				if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+					t.Errorf("test %d/%d expected temporary files", i, budget)
+				}
+			}
+			buf := new(bytes.Buffer)
+			if err := EncodeCSV(buf, view, WithSkipHeader(true)); err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, budget, err)
+			}
+			if actual := strings.TrimSpace(buf.String()); actual != test.exp {
+				t.Errorf("test %d/%d expected:\n%s\n---\ngot:\n%s", i, budget, test.exp, actual)
+			}
+			if err := view.Close(); err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, budget, err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("test %d/%d expected no temporary files, got: %d", i, budget, len(entries))
+			}
+		}
+	}
+	if _, err := NewSortView(rs(), WithSortKeys(SortKey{Column: "foo"})); err != ErrSortColumnNotInResult {
+		t.Errorf("expected error %v, got: %v", ErrSortColumnNotInResult, err)
+	}
+}
+
+func TestRowSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		row []any
+		exp int64
+	}{
+		{[]any{}, 24},
+		{[]any{nil, nil}, 24 + 2*16},
+		{[]any{int64(1), "ab", []byte("abc"), time.Time{}}, 24 + 4*16 + 8 + 18 + 27 + 24},
+	}
+	for i, test := range tests {
+		if size := rowSize(test.row); size != test.exp {
+			t.Errorf("test %d expected %d, got: %d", i, test.exp, size)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tblfmt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+)
+
+// Stream is a stream of rows for a [StreamResultSet]. One of Chan or Seq
+// should be set.
+type Stream struct {
+	// Columns are the column names.
+	Columns []string
+	// Chan is a channel of rows, closed by the producer when done. When the
+	// stream is stopped early (ie, the context is canceled), the remaining rows
+	// are drained until the channel is closed.
+	Chan <-chan []any
+	// Seq is a sequence of rows. The sequence is iterated in a separate
+	// goroutine.
+	Seq iter.Seq[[]any]
+	// Err, when not nil, is called after the last row to retrieve any error
+	// encountered by the producer (ie, errgroup.Group.Wait).
+	Err func() error
+}
+
+// StreamResultSet is a result set fed by channels or iterators, allowing
+// encoders to write rows as they are produced (see [WithCount]). Each stream
+// is a separate result set.
+type StreamResultSet struct {
+	// ctx is the context.
+	ctx context.Context
+	// streams are the streams.
+	streams []Stream
+	// ch is the current channel.
+	ch <-chan []any
+	// done stops the current sequence.
+	done chan struct{}
+	// row is the current row.
+	row []any
+	// err is the last encountered error.
+	err error
+}
+
+// FromChan creates a result set for the rows received from a channel. See
+// [FromStreams].
+func FromChan(ctx context.Context, columns []string, ch <-chan []any) *StreamResultSet {
+	return FromStreams(ctx, Stream{Columns: columns, Chan: ch})
+}
+
+// FromSeq creates a result set for the rows of a sequence. See [FromStreams].
+func FromSeq(ctx context.Context, columns []string, seq iter.Seq[[]any]) *StreamResultSet {
+	return FromStreams(ctx, Stream{Columns: columns, Seq: seq})
+}
+
+// FromStreams creates a result set for the rows of the streams, with each
+// stream being a separate result set.
+//
+// When the context is canceled, Next returns false and Err returns the
+// context's error. Any sequence being iterated is stopped when it next yields
+// a row, and any channel is drained in a separate goroutine until closed by
+// its producer. A nil context is treated as [context.Background].
+func FromStreams(ctx context.Context, streams ...Stream) *StreamResultSet {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := &StreamResultSet{
+		ctx:     ctx,
+		streams: streams,
+	}
+	r.open()
+	return r
+}
+
+// open opens the first stream.
+func (r *StreamResultSet) open() {
+	if len(r.streams) == 0 {
+		return
+	}
+	s := r.streams[0]
+	if s.Seq == nil {
+		r.ch = s.Chan
+		return
+	}
+	ch, done := make(chan []any), make(chan struct{})
+	go func() {
+		defer close(ch)
+		for row := range s.Seq {
+			select {
+			case ch <- row:
+			case <-done:
+				return
+			case <-r.ctx.Done():
+				return
+			}
+		}
+	}()
+	r.ch, r.done = ch, done
+}
+
+// stop stops the current stream, draining the current channel so that its
+// producer is not blocked.
+func (r *StreamResultSet) stop() {
+	switch {
+	case r.done != nil:
+		close(r.done)
+	case r.ch != nil:
+		go func(ch <-chan []any) {
+			for range ch {
+			}
+		}(r.ch)
+	}
+	r.ch, r.done, r.row = nil, nil, nil
+}
+
+// Next satisfies the ResultSet interface.
+func (r *StreamResultSet) Next() bool {
+	if r.err != nil || r.ch == nil {
+		return false
+	}
+	select {
+	case row, ok := <-r.ch:
+		if ok {
+			r.row = row
+			return true
+		}
+		r.ch = nil
+		r.stop()
+		if r.err = r.ctx.Err(); r.err == nil && r.streams[0].Err != nil {
+			r.err = r.streams[0].Err()
+		}
+	case <-r.ctx.Done():
+		r.stop()
+		r.err = r.ctx.Err()
+	}
+	return false
+}
+
+// Scan satisfies the ResultSet interface.
+func (r *StreamResultSet) Scan(dest ...any) error {
+	if r.row == nil {
+		return sql.ErrNoRows
+	}
+	cols := r.streams[0].Columns
+	if len(dest) != len(cols) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(cols), len(dest))
+	}
+	if len(r.row) != len(cols) {
+		return fmt.Errorf("expected %d values in row, not %d", len(cols), len(r.row))
+	}
+	for i := range dest {
+		if err := assign(dest[i], r.row[i], -1); err != nil {
+			return fmt.Errorf("column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (r *StreamResultSet) Columns() ([]string, error) {
+	if len(r.streams) == 0 {
+		return nil, ErrResultSetHasNoColumns
+	}
+	return r.streams[0].Columns, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (r *StreamResultSet) Close() error {
+	r.stop()
+	r.streams = nil
+	return nil
+}
+
+// Err satisfies the ResultSet interface.
+func (r *StreamResultSet) Err() error {
+	return r.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (r *StreamResultSet) NextResultSet() bool {
+	if r.err != nil || len(r.streams) < 2 {
+		return false
+	}
+	r.stop()
+	r.streams = r.streams[1:]
+	r.open()
+	return true
+}
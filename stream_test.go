@@ -0,0 +1,140 @@
+package tblfmt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFromStreams(t *testing.T) {
+	t.Parallel()
+	ch := make(chan []any)
+	go func() {
+		defer close(ch)
+		for i := range 3 {
+			ch <- []any{i, "row"}
+		}
+	}()
+	rs := FromStreams(context.Background(), Stream{
+		Columns: []string{"n", "s"},
+		Chan:    ch,
+	}, Stream{
+		Columns: []string{"v"},
+		Seq:     slices.Values([][]any{{"a"}, {"b"}}),
+	})
+	exp := ` n |  s  
+---+-----
+ 0 | row 
+ 1 | row 
+ 2 | row 
+(3 rows)
+
+ v 
+---
+ a 
+ b 
+(2 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTableAll(buf, rs, WithCount(2)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestFromStreamsErrors(t *testing.T) {
+	t.Parallel()
+	errProducer := errors.New("producer error")
+	rs := FromStreams(context.Background(), Stream{
+		Columns: []string{"v"},
+		Seq:     slices.Values([][]any{{1}}),
+		Err: func() error {
+			return errProducer
+		},
+	})
+	if err := EncodeTable(new(bytes.Buffer), rs); !errors.Is(err, errProducer) {
+		t.Errorf("expected error %v, got: %v", errProducer, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rs = FromSeq(ctx, []string{"v"}, func(yield func([]any) bool) {
+		for i := 0; ; i++ {
+			if i == 5 {
+				cancel()
+			}
+			if !yield([]any{i}) {
+				return
+			}
+		}
+	})
+	if err := EncodeTable(new(bytes.Buffer), rs, WithCount(1)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestFromStreamsCancel(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, done := make(chan []any), make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+		for i := range 100 {
+			ch <- []any{i}
+		}
+	}()
+	rs := FromChan(ctx, []string{"v"}, ch)
+	if !rs.Next() {
+		t.Fatalf("expected a row")
+	}
+	cancel()
+	for rs.Next() {
+	}
+	if err := rs.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v, got: %v", context.Canceled, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected producer to not be blocked")
+	}
+}
+
+func TestFromStreamsShortRow(t *testing.T) {
+	t.Parallel()
+	rs := FromSeq(context.Background(), []string{"a", "b"}, slices.Values([][]any{{1}}))
+	if !rs.Next() {
+		t.Fatalf("expected a row")
+	}
+	var a, b any
+	if err := rs.Scan(&a, &b); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	if err := rs.Scan(&a); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func TestFromStreamsNilContext(t *testing.T) {
+	t.Parallel()
+	var ctx context.Context
+	ch := make(chan []any, 1)
+	ch <- []any{"b"}
+	close(ch)
+	for i, rs := range []*StreamResultSet{
+		FromSeq(ctx, []string{"v"}, slices.Values([][]any{{"b"}})),
+		FromChan(ctx, []string{"v"}, ch),
+	} {
+		buf := new(bytes.Buffer)
+		if err := EncodeCSV(buf, rs); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if exp, actual := "v\nb\n", buf.String(); actual != exp {
+			t.Errorf("test %d expected %q, got: %q", i, exp, actual)
+		}
+	}
+}
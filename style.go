@@ -47,6 +47,24 @@ func (style TransformStyle) Transform(s string) string {
 	return s
 }
 
+// HeaderGroup is a header group spanning contiguous columns.
+type HeaderGroup struct {
+	// Name is the header group name. Columns in a group with an empty name
+	// are not grouped.
+	Name string
+	// Span is the number of columns spanned by the group.
+	Span int
+}
+
+// HeaderSpan is a formatted header group.
+type HeaderSpan struct {
+	// Value is the formatted header group name, or nil for an ungrouped
+	// column.
+	Value *Value
+	// Span is the number of columns spanned.
+	Span int
+}
+
 // LineStyle is a table line style.
 //
 // See the ASCII, OldASCII, and Unicode styles below for predefined table
@@ -0,0 +1,558 @@
+package tblfmt
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// TableResultSet is a result set for tables parsed from aligned table output
+// (ie, as written by the [TableEncoder] or psql). Each table is a separate
+// result set.
+type TableResultSet struct {
+	// tables are the parsed tables.
+	tables []*parsedTable
+	// row is the current row position.
+	row int
+}
+
+// FromTable creates a result set for the aligned tables read from the reader,
+// with each table being a separate result set.
+//
+// Tables using any of the built-in line styles and any border level can be
+// parsed. Columns are determined by the divider below the header row, or when
+// there is no divider (ie, [TableLineStyle]), by the positions that are blank
+// on every line (in which case the table must not have a title). Lines ending
+// a value with a newline wrap marker ('+' or '↵'), or continuing a value with
+// a ':' separator, are joined with a newline. Any title, border, and divider
+// lines are skipped, and a table ends at a "(N rows)" summary, an empty line,
+// or the end of the input.
+//
+// When the summary has fewer rows than parsed, and the table has no newline
+// wrap markers (ie, [OldASCIILineStyle]), a line with a blank first column is
+// treated as continuing the values of the row above. As such tables do not
+// mark which values continue, a value's trailing newlines are not recovered.
+//
+// Empty values are scanned as nil. The alignment of each column is recovered
+// from the padding of its values, and values of right or center aligned
+// columns are scanned as an [Aligned] value.
+func FromTable(r io.Reader) (*TableResultSet, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<24)
+	for s.Scan() {
+		lines = append(lines, strings.TrimSuffix(s.Text(), "\r"))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	var tables []*parsedTable
+	for len(lines) != 0 {
+		var t *parsedTable
+		t, lines = parseTable(lines)
+		if t != nil {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, ErrResultSetHasNoColumns
+	}
+	return &TableResultSet{
+		tables: tables,
+		row:    -1,
+	}, nil
+}
+
+// Next satisfies the ResultSet interface.
+func (r *TableResultSet) Next() bool {
+	if len(r.tables) == 0 {
+		return false
+	}
+	if r.row < len(r.tables[0].rows) {
+		r.row++
+	}
+	return r.row < len(r.tables[0].rows)
+}
+
+// Scan satisfies the ResultSet interface.
+func (r *TableResultSet) Scan(dest ...any) error {
+	if len(r.tables) == 0 || r.row < 0 || len(r.tables[0].rows) <= r.row {
+		return sql.ErrNoRows
+	}
+	t := r.tables[0]
+	if len(dest) != len(t.cols) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(t.cols), len(dest))
+	}
+	for i := range dest {
+		var val any
+		if v := t.rows[r.row][i]; v != nil {
+			val = *v
+		}
+		align := t.aligns[i]
+		if align == AlignLeft {
+			align = -1
+		}
+		if err := assign(dest[i], val, align); err != nil {
+			return fmt.Errorf("column %d (%s): %w", i, t.cols[i], err)
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (r *TableResultSet) Columns() ([]string, error) {
+	if len(r.tables) == 0 {
+		return nil, ErrResultSetHasNoColumns
+	}
+	return r.tables[0].cols, nil
+}
+
+// Aligns returns the recovered alignment of each column.
+func (r *TableResultSet) Aligns() []Align {
+	if len(r.tables) == 0 {
+		return nil
+	}
+	return r.tables[0].aligns
+}
+
+// Count returns the row count from the table's summary, if any.
+func (r *TableResultSet) Count() (int, bool) {
+	if len(r.tables) == 0 || r.tables[0].count < 0 {
+		return 0, false
+	}
+	return r.tables[0].count, true
+}
+
+// Close satisfies the ResultSet interface.
+func (r *TableResultSet) Close() error {
+	r.tables = nil
+	return nil
+}
+
+// Err satisfies the ResultSet interface.
+func (r *TableResultSet) Err() error {
+	return nil
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (r *TableResultSet) NextResultSet() bool {
+	if len(r.tables) < 2 {
+		return false
+	}
+	r.tables, r.row = r.tables[1:], -1
+	return true
+}
+
+// parsedTable is a parsed table.
+type parsedTable struct {
+	// cols are the column names.
+	cols []string
+	// aligns are the column alignments.
+	aligns []Align
+	// rows are the rows.
+	rows [][]*string
+	// count is the summary row count, or -1 when there is no summary.
+	count int
+}
+
+// tableSummaryRE matches a table summary.
+var tableSummaryRE = regexp.MustCompile(`^\((\d+) rows?\)$`)
+
+// Table runes, built from the built-in line styles.
+var (
+	// tableHorizontals are the horizontal line runes.
+	tableHorizontals = make(map[rune]bool)
+	// tableJunctions are the border, junction, and separator runes.
+	tableJunctions = make(map[rune]bool)
+	// tableWrappers are the newline wrap marker runes.
+	tableWrappers = make(map[rune]bool)
+	// tableContinuations are the continuation separator runes.
+	tableContinuations = make(map[rune]bool)
+)
+
+func init() {
+	for _, style := range []LineStyle{
+		ASCIILineStyle(),
+		OldASCIILineStyle(),
+		UnicodeLineStyle(),
+		UnicodeDoubleLineStyle(),
+	} {
+		for _, r := range [][4]rune{style.Top, style.Mid, style.End} {
+			tableHorizontals[r[1]] = true
+			tableJunctions[r[0]], tableJunctions[r[2]], tableJunctions[r[3]] = true, true, true
+		}
+		tableJunctions[style.Row[0]], tableJunctions[style.Row[2]], tableJunctions[style.Row[3]] = true, true, true
+		if style.Wrap[1] != ' ' {
+			tableWrappers[style.Wrap[1]] = true
+		}
+		if style.Wrap[2] != style.Row[2] {
+			tableContinuations[style.Wrap[2]] = true
+		}
+	}
+}
+
+// tableColumn is the position of a column in a table.
+type tableColumn struct {
+	// start and end are the content positions.
+	start, end int
+	// wrap is the newline wrap marker position.
+	wrap int
+	// sep is the preceding separator position, or -1 when none.
+	sep int
+}
+
+// parseTable parses the first table in lines, returning the remaining lines.
+func parseTable(lines []string) (*parsedTable, []string) {
+	// determine table end
+	n, count := len(lines), -1
+	for i, line := range lines {
+		if m := tableSummaryRE.FindStringSubmatch(line); m != nil {
+			n, count = i, atoi(m[1])
+			break
+		}
+		if line == "" && i != 0 {
+			n = i
+			break
+		}
+	}
+	block, rest := lines[:n], lines[n:]
+	if len(rest) != 0 {
+		rest = rest[1:]
+	}
+	tl := make([]tableLine, len(block))
+	for i, line := range block {
+		tl[i] = newTableLine(line)
+	}
+	// find header divider
+	header, cols := -1, []tableColumn(nil)
+	for i := 1; i < len(tl); i++ {
+		if tl[i].isDivider() && !tl[i-1].isDivider() && tl[i-1].matches(tl[i]) {
+			header, cols = i-1, tl[i].columns(tl[i-1:])
+			break
+		}
+	}
+	var body []tableLine
+	switch {
+	case header != -1:
+		body = tl[header+2:]
+	default:
+		// no divider, skip leading blank lines
+		for len(tl) != 0 && strings.TrimSpace(tl[0].String()) == "" {
+			tl = tl[1:]
+		}
+		if len(tl) == 0 {
+			return nil, rest
+		}
+		header, cols, body = 0, blankColumns(tl), tl[1:]
+	}
+	if len(cols) == 0 {
+		return nil, rest
+	}
+	t := &parsedTable{
+		cols:   make([]string, len(cols)),
+		aligns: make([]Align, len(cols)),
+		count:  count,
+	}
+	for i, col := range cols {
+		t.cols[i] = strings.TrimSpace(tl[header].slice(col.start, col.end))
+	}
+	// collect row lines
+	var rows [][]tableLine
+	var wrapped, open bool
+	for _, l := range body {
+		if l.isDivider() {
+			continue
+		}
+		cont := open
+		for _, col := range cols {
+			if col.sep != -1 && tableContinuations[l.at(col.sep)] {
+				cont = true
+			}
+		}
+		if cont && len(rows) != 0 {
+			rows[len(rows)-1] = append(rows[len(rows)-1], l)
+		} else {
+			rows = append(rows, []tableLine{l})
+		}
+		open = false
+		for _, col := range cols {
+			if tableWrappers[l.at(col.wrap)] {
+				open, wrapped = true, true
+			}
+		}
+	}
+	// join lines with a blank first column when the summary count is less
+	// than the row count
+	if !wrapped && count != -1 && count < len(rows) {
+		var joined [][]tableLine
+		for _, row := range rows {
+			if len(joined) != 0 && strings.TrimSpace(row[0].slice(cols[0].start, cols[0].end)) == "" {
+				joined[len(joined)-1] = append(joined[len(joined)-1], row...)
+			} else {
+				joined = append(joined, row)
+			}
+		}
+		rows = joined
+	}
+	// recover alignments
+	for i, col := range cols {
+		t.aligns[i] = recoverAlign(rows, col)
+	}
+	// build values
+	for _, row := range rows {
+		vals := make([]*string, len(cols))
+		for i, col := range cols {
+			lines := make([]string, len(row))
+			var last int
+			for j, l := range row {
+				v := l.slice(col.start, col.end)
+				switch t.aligns[i] {
+				case AlignLeft:
+					v = strings.TrimRight(v, " ")
+				case AlignRight:
+					v = strings.TrimLeft(v, " ")
+				default:
+					v = strings.TrimSpace(v)
+				}
+				if lines[j] = v; v != "" {
+					last = j
+				}
+			}
+			var s []string
+			for j, v := range lines {
+				// only include lines continuing the value, which without
+				// wrap markers are the lines up to its last non-blank line
+				if j == 0 || tableWrappers[row[j-1].at(col.wrap)] || col.sep != -1 && tableContinuations[row[j].at(col.sep)] || !wrapped && j <= last {
+					s = append(s, v)
+				}
+			}
+			if v := strings.Join(s, "\n"); v != "" {
+				vals[i] = &v
+			}
+		}
+		t.rows = append(t.rows, vals)
+	}
+	return t, rest
+}
+
+// recoverAlign recovers the alignment of a column from the padding of the
+// first line of each row's value.
+func recoverAlign(rows [][]tableLine, col tableColumn) Align {
+	var left, right, center int
+	for _, row := range rows {
+		l, r, ok := row[0].padding(col.start, col.end)
+		switch {
+		case !ok:
+		case l == 0 && r != 0:
+			left++
+		case l != 0 && r == 0:
+			right++
+		case l != 0 && r != 0 && (l-r <= 1 && r-l <= 1):
+			center++
+		}
+	}
+	switch {
+	case left != 0:
+		return AlignLeft
+	case right != 0 && center == 0:
+		return AlignRight
+	case center != 0 && right == 0:
+		return AlignCenter
+	}
+	return AlignLeft
+}
+
+// blankColumns returns the columns separated by the positions that are blank
+// on every line.
+func blankColumns(lines []tableLine) []tableColumn {
+	var width int
+	for _, l := range lines {
+		width = max(width, l.width())
+	}
+	used := make([]bool, width)
+	for _, l := range lines {
+		for _, r := range l {
+			if r.r != ' ' {
+				for i := r.pos; i < r.pos+r.width; i++ {
+					used[i] = true
+				}
+			}
+		}
+	}
+	var cols []tableColumn
+	for i := 0; i < width; i++ {
+		if !used[i] {
+			continue
+		}
+		start := i
+		for i < width && used[i] {
+			i++
+		}
+		cols = append(cols, tableColumn{start: start, end: i, wrap: i, sep: -1})
+	}
+	return cols
+}
+
+// tableLine is a line of a table.
+type tableLine []tableRune
+
+// tableRune is a rune of a table line, and its display position and width.
+type tableRune struct {
+	r          rune
+	pos, width int
+}
+
+// newTableLine creates a table line, expanding tabs to 8 character tab stops.
+func newTableLine(s string) tableLine {
+	var l tableLine
+	var pos int
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if r == '\t' {
+			w = 8 - pos%8
+		}
+		l = append(l, tableRune{r: r, pos: pos, width: w})
+		pos += w
+	}
+	return l
+}
+
+// String satisfies the fmt.Stringer interface.
+func (l tableLine) String() string {
+	var sb strings.Builder
+	for _, r := range l {
+		sb.WriteRune(r.r)
+	}
+	return sb.String()
+}
+
+// width returns the display width of the line.
+func (l tableLine) width() int {
+	if len(l) == 0 {
+		return 0
+	}
+	return l[len(l)-1].pos + l[len(l)-1].width
+}
+
+// at returns the rune at the display position, or a space.
+func (l tableLine) at(pos int) rune {
+	i, ok := slices.BinarySearchFunc(l, pos, func(r tableRune, pos int) int {
+		return r.pos - pos
+	})
+	if !ok {
+		return ' '
+	}
+	return l[i].r
+}
+
+// slice returns the runes starting at the display positions start through
+// end.
+func (l tableLine) slice(start, end int) string {
+	var sb strings.Builder
+	for _, r := range l {
+		if start <= r.pos && r.pos < end {
+			sb.WriteRune(r.r)
+		}
+	}
+	return sb.String()
+}
+
+// padding returns the number of leading and trailing blank display positions
+// between start and end, returning false when all positions are blank.
+func (l tableLine) padding(start, end int) (int, int, bool) {
+	first, last := -1, -1
+	for _, r := range l {
+		if start <= r.pos && r.pos < end && r.r != ' ' {
+			if first == -1 {
+				first = r.pos
+			}
+			last = r.pos + r.width
+		}
+	}
+	if first == -1 {
+		return 0, 0, false
+	}
+	return first - start, max(0, end-last), true
+}
+
+// isDivider returns true when the line only contains horizontal line and
+// junction runes, and at least one horizontal line rune.
+func (l tableLine) isDivider() bool {
+	var horizontal bool
+	for _, r := range l {
+		switch {
+		case tableHorizontals[r.r]:
+			horizontal = true
+		case r.r != ' ' && !tableJunctions[r.r]:
+			return false
+		}
+	}
+	return horizontal
+}
+
+// matches returns true when the line has a separator at the position of each
+// of the divider's junctions.
+func (l tableLine) matches(divider tableLine) bool {
+	for _, r := range divider {
+		if !tableHorizontals[r.r] && r.r != ' ' && !tableJunctions[l.at(r.pos)] && !tableContinuations[l.at(r.pos)] {
+			return false
+		}
+	}
+	return true
+}
+
+// columns returns the columns of a divider, using the header and body lines
+// to determine the border for single column tables.
+func (l tableLine) columns(lines []tableLine) []tableColumn {
+	var cols []tableColumn
+	var junction bool
+	for i := 0; i < len(l); i++ {
+		if !tableHorizontals[l[i].r] {
+			junction = junction || l[i].r != ' '
+			continue
+		}
+		start := l[i].pos
+		for i < len(l) && tableHorizontals[l[i].r] {
+			i++
+		}
+		end := l.width()
+		if i < len(l) {
+			end = l[i].pos
+		}
+		cols = append(cols, tableColumn{start: start, end: end})
+		i--
+	}
+	// single column tables with border 1 have a leading space
+	if len(cols) == 1 && !junction {
+		junction = true
+		for _, line := range lines {
+			if !line.isDivider() && line.width() != 0 && line.at(0) != ' ' {
+				junction = false
+			}
+		}
+	}
+	for i := range cols {
+		if junction {
+			// border >= 1: spacer, value, wrap marker
+			cols[i].sep, cols[i].wrap = cols[i].start-1, cols[i].end-1
+			cols[i].start, cols[i].end = cols[i].start+1, cols[i].end-1
+		} else {
+			// border 0: value, wrap marker
+			cols[i].sep, cols[i].wrap = -1, cols[i].end
+		}
+	}
+	return cols
+}
+
+// atoi converts s to an int, returning 0 on error.
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
@@ -0,0 +1,159 @@
+package tblfmt
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xo/tblfmt/internal"
+)
+
+func TestFromTable(t *testing.T) {
+	t.Parallel()
+	rs := func() ResultSet {
+		return internal.New([]string{"id", "name", "z"}, [][]any{
+			{1, "a\tb", "x\ny"},
+			{22, "", nil},
+			{3, "cc\n  dd", "三"},
+		}, [][]any{
+			{4, "e", nil},
+		})
+	}
+	styles := []LineStyle{
+		ASCIILineStyle(),
+		OldASCIILineStyle(),
+		UnicodeLineStyle(),
+		UnicodeDoubleLineStyle(),
+	}
+	for i, style := range styles {
+		for border := range 3 {
+			opts := []Option{WithLineStyle(style), WithBorder(border)}
+			exp := new(bytes.Buffer)
+			if err := EncodeTableAll(exp, rs(), opts...); err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, border, err)
+			}
+			r, err := FromTable(bytes.NewReader(exp.Bytes()))
+			if err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, border, err)
+			}
+			buf := new(bytes.Buffer)
+			if err := EncodeTableAll(buf, r, opts...); err != nil {
+				t.Fatalf("test %d/%d expected no error, got: %v", i, border, err)
+			}
+			if actual := buf.String(); actual != exp.String() {
+				t.Errorf("test %d/%d expected:\n%s\n---\ngot:\n%s", i, border, exp, actual)
+			}
+		}
+	}
+}
+
+func TestFromTableLineStyle(t *testing.T) {
+	t.Parallel()
+	s := `NAME  QTY  NOTE
+a       1  x y
+bb     22
+(2 rows)
+`
+	r, err := FromTable(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp, aligns := []Align{AlignLeft, AlignRight, AlignLeft}, r.Aligns(); !slices.Equal(aligns, exp) {
+		t.Errorf("expected aligns %v, got: %v", exp, aligns)
+	}
+	exp := `[{"NAME":"a","QTY":"1","NOTE":"x y"},{"NAME":"bb","QTY":"22","NOTE":null}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, r); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%s\n---\ngot:\n%s", exp, actual)
+	}
+}
+
+func TestFromTableContinuation(t *testing.T) {
+	t.Parallel()
+	s := ` id |  name
+----+--------
+  1 | foo
+    : bar
+  2 | baz
+(2 rows)
+`
+	r, err := FromTable(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	exp := `[{"id":"1","name":"foo\nbar"},{"id":"2","name":"baz"}]`
+	buf := new(bytes.Buffer)
+	if err := EncodeJSON(buf, r); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%s\n---\ngot:\n%s", exp, actual)
+	}
+}
+
+func TestFromTableBlankLine(t *testing.T) {
+	t.Parallel()
+	rs := internal.New([]string{"id", "name"}, [][]any{
+		{1, "a\n\nb"},
+		{2, "c"},
+	})
+	for border := range 3 {
+		opts := []Option{WithLineStyle(OldASCIILineStyle()), WithBorder(border)}
+		exp := new(bytes.Buffer)
+		if err := EncodeTable(exp, rs, opts...); err != nil {
+			t.Fatalf("border %d expected no error, got: %v", border, err)
+		}
+		rs.Reset()
+		r, err := FromTable(bytes.NewReader(exp.Bytes()))
+		if err != nil {
+			t.Fatalf("border %d expected no error, got: %v", border, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := EncodeTable(buf, r, opts...); err != nil {
+			t.Fatalf("border %d expected no error, got: %v", border, err)
+		}
+		if actual := buf.String(); actual != exp.String() {
+			t.Errorf("border %d expected:\n%s\n---\ngot:\n%s", border, exp, actual)
+		}
+	}
+}
+
+func TestFromTableGolden(t *testing.T) {
+	t.Parallel()
+	for _, test := range loadTests(t, "big") {
+		if test.opts["format"] != "aligned" || test.opts["expanded"] == "on" {
+			continue
+		}
+		r, err := FromTable(bytes.NewReader(test.exp))
+		if err != nil {
+			t.Fatalf("%s expected no error, got: %v", test.gld, err)
+		}
+		// re-encoding the parsed cells reproduces the golden output
+		f, opts := FromMap(test.opts)
+		enc, err := f(r, opts...)
+		if err != nil {
+			t.Fatalf("%s expected no error, got: %v", test.gld, err)
+		}
+		buf := new(bytes.Buffer)
+		if err := enc.EncodeAll(buf); err != nil {
+			t.Fatalf("%s expected no error, got: %v", test.gld, err)
+		}
+		exp, actual := string(test.exp), buf.String()
+		if test.opts["linestyle"] == "old-ascii" {
+			// without wrap markers, a trailing newline in a value is drawn as
+			// a blank line that cannot be recovered
+			exp, actual = blankLineRE.ReplaceAllString(exp, ""), blankLineRE.ReplaceAllString(actual, "")
+		}
+		if actual != exp {
+			t.Errorf("%s expected:\n%s\n---\ngot:\n%s", test.gld, exp, actual)
+		}
+	}
+}
+
+// blankLineRE matches table lines without any values.
+var blankLineRE = regexp.MustCompile(`(?m)^[ |]*\n`)
@@ -205,6 +205,26 @@ func EncodeAsciiDocAll(w io.Writer, resultSet ResultSet, opts ...Option) error {
 	return enc.EncodeAll(w)
 }
 
+// EncodeLaTeX encodes the result set to the writer using the LaTeX template
+// and the supplied encoding options.
+func EncodeLaTeX(w io.Writer, resultSet ResultSet, opts ...Option) error {
+	enc, err := NewLaTeXEncoder(resultSet, opts...)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(w)
+}
+
+// EncodeLaTeXAll encodes all result sets to the writer using the LaTeX
+// template and the supplied encoding options.
+func EncodeLaTeXAll(w io.Writer, resultSet ResultSet, opts ...Option) error {
+	enc, err := NewLaTeXEncoder(resultSet, opts...)
+	if err != nil {
+		return err
+	}
+	return enc.EncodeAll(w)
+}
+
 // EncodeVertical encodes the result set to the writer using the vertical
 // template and the supplied encoding options.
 func EncodeVertical(w io.Writer, resultSet ResultSet, opts ...Option) error {
@@ -255,6 +275,10 @@ const (
 	ErrInvalidCSVFieldSeparator Error = "invalid csv field separator"
 	// ErrInvalidColumnParams is the invalid column params error.
 	ErrInvalidColumnParams Error = "invalid column params"
+	// ErrInvalidColumnPattern is the invalid column pattern error.
+	ErrInvalidColumnPattern Error = "invalid column pattern"
+	// ErrInvalidTopK is the invalid top k error.
+	ErrInvalidTopK Error = "invalid top k"
 	// ErrCrosstabResultMustHaveAtLeast3Columns is the crosstab result must
 	// have at least 3 columns error.
 	ErrCrosstabResultMustHaveAtLeast3Columns Error = "crosstab result must have at least 3 columns"
@@ -280,9 +304,53 @@ const (
 	// ErrCrosstabDuplicateVerticalAndHorizontalValue is the crosstab duplicate
 	// vertical and horizontal value error.
 	ErrCrosstabDuplicateVerticalAndHorizontalValue Error = "crosstab duplicate vertical and horizontal value"
+	// ErrCrosstabTotalLabelIsAValue is the crosstab total label is a value
+	// error.
+	ErrCrosstabTotalLabelIsAValue Error = "crosstab total label is a value"
 	// ErrCrosstabHorizontalSortColumnIsNotANumber is the crosstab horizontal
 	// sort column is not a number error.
+	//
+	// Deprecated: the horizontal sort column may be of any type.
 	ErrCrosstabHorizontalSortColumnIsNotANumber Error = "crosstab horizontal sort column is not a number"
+	// ErrTransposeKeyColumnNotInResult is the transpose key column not in
+	// result error.
+	ErrTransposeKeyColumnNotInResult Error = "transpose key column not in result"
+	// ErrSortColumnNotInResult is the sort column not in result error.
+	ErrSortColumnNotInResult Error = "sort column not in result"
+	// ErrDiffColumnsDoNotMatch is the diff columns do not match error.
+	ErrDiffColumnsDoNotMatch Error = "diff columns do not match"
+	// ErrDiffKeyColumnNotInResult is the diff key column not in result error.
+	ErrDiffKeyColumnNotInResult Error = "diff key column not in result"
+	// ErrDiffNoKeyColumns is the diff no key columns error.
+	ErrDiffNoKeyColumns Error = "diff no key columns"
+	// ErrColumnNotInResult is the column not in result error.
+	ErrColumnNotInResult Error = "column not in result"
+	// ErrUnpivotIDColumnNotInResult is the unpivot id column not in result
+	// error.
+	ErrUnpivotIDColumnNotInResult Error = "unpivot id column not in result"
+	// ErrUnpivotValueColumnNotInResult is the unpivot value column not in
+	// result error.
+	ErrUnpivotValueColumnNotInResult Error = "unpivot value column not in result"
+	// ErrFilterPredicateIsNil is the filter predicate is nil error.
+	ErrFilterPredicateIsNil Error = "filter predicate is nil"
+	// ErrGroupByColumnNotInResult is the group by column not in result error.
+	ErrGroupByColumnNotInResult Error = "group by column not in result"
+	// ErrSubtotalColumnNotInResult is the subtotal column not in result error.
+	ErrSubtotalColumnNotInResult Error = "subtotal column not in result"
+	// ErrRepeatedColumnNotInResult is the repeated column not in result error.
+	ErrRepeatedColumnNotInResult Error = "repeated column not in result"
+	// ErrInvalidHeaderGroups is the invalid header groups error.
+	ErrInvalidHeaderGroups Error = "invalid header groups"
+	// ErrValueIsNotASlice is the value is not a slice error.
+	ErrValueIsNotASlice Error = "value is not a slice"
+	// ErrSliceHasInvalidElementType is the slice has invalid element type
+	// error.
+	ErrSliceHasInvalidElementType Error = "slice has invalid element type"
+	// ErrInvalidJSONRow is the invalid json row error.
+	ErrInvalidJSONRow Error = "invalid json row"
+	// ErrJSONArrayRowsRequireColumnNames is the json array rows require column
+	// names error.
+	ErrJSONArrayRowsRequireColumnNames Error = "json array rows require column names"
 )
 
 // newline is the default newline used by the system.
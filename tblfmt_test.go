@@ -334,3 +334,479 @@ func testWriteTemplateTo(w io.Writer, tpl *Template) error {
 	}
 	return nil
 }
+
+func TestEncodeTableGroupBy(t *testing.T) {
+	t.Parallel()
+	exp := ` region   | name | qty 
+----------+------+-----
+ east     | a    |   1 
+          | b    |   2 
+----------+------+-----
+ subtotal |      |   3 
+----------+------+-----
+ west     | c    |   4 
+          | d    | 1.5 
+----------+------+-----
+ subtotal |      | 5.5 
+(4 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, groupRset(), WithGroupBy("region", "qty"), WithFormatterOptions(WithHeaderAlign(AlignLeft))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+	exp = ` region | name | qty 
+--------+------+-----
+ east   | a    |   1 
+        | b    |   2 
+--------+------+-----
+ Σ      |      |   3 
+--------+------+-----
+ west   | c    |   4 
+        | d    | 1.5 
+--------+------+-----
+ Σ      |      | 5.5 
+(4 rows)
+`
+	buf.Reset()
+	if err := EncodeTable(buf, groupRset(), WithGroupBy("region", "qty"), WithSubtotalLabel("Σ")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeExpandedGroupBy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		opts []Option
+		exp  string
+	}{
+		{[]Option{WithBorder(2)}, `+-[ RECORD 1, region: east ]-+
+| region | east              |
+| name   | a                 |
+| qty    | 1                 |
++-[ RECORD 2 ]---------------+
+| region |                   |
+| name   | b                 |
+| qty    | 2                 |
++-[ SUBTOTAL ]---------------+
+| region | subtotal          |
+| name   |                   |
+| qty    | 3                 |
++-[ RECORD 3, region: west ]-+
+| region | west              |
+| name   | c                 |
+| qty    | 4                 |
++-[ RECORD 4 ]---------------+
+| region |                   |
+| name   | d                 |
+| qty    | 1.5               |
++-[ SUBTOTAL ]---------------+
+| region | subtotal          |
+| name   |                   |
+| qty    | 5.5               |
++--------+-------------------+
+`},
+		{[]Option{WithBorder(1), WithSkipHeader(true)}, `-[ region: east ]--
+ region | east 
+ name   | a 
+ qty    | 1 
+ region |  
+ name   | b 
+ qty    | 2 
+ region | subtotal 
+ name   |  
+ qty    | 3 
+-[ region: west ]--
+ region | west 
+ name   | c 
+ qty    | 4 
+ region |  
+ name   | d 
+ qty    | 1.5 
+ region | subtotal 
+ name   |  
+ qty    | 5.5 
+`},
+	}
+	for i, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := EncodeExpanded(buf, groupRset(), append(test.opts, WithGroupBy("region", "qty"))...); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if actual := buf.String(); actual != test.exp {
+			t.Errorf("test %d expected:\n%q\n---\ngot:\n%q", i, test.exp, actual)
+		}
+	}
+}
+
+func TestEncodeExpandedFormatterOptions(t *testing.T) {
+	t.Parallel()
+	rs := internal.New([]string{"bin", "doc", "amount"}, [][]any{
+		{[]byte{0, 1, 2, 'a'}, `{"a":1}`, 1234.5},
+		{[]byte{0xff}, `[1]`, 42},
+	})
+	exp := `-[ RECORD 1 ]----------------------------------------------------------------
+ bin    | 00000000  00 01 02 61                                       |...a| 
+ doc    | {                                                                 +
+        |   "a": 1                                                          +
+        | } 
+ amount | 1.234,5 
+-[ RECORD 2 ]----------------------------------------------------------------
+ bin    | 00000000  ff                                                |.| 
+ doc    | [                                                                 +
+        |   1                                                               +
+        | ] 
+ amount |    42 
+`
+	buf := new(bytes.Buffer)
+	opts := WithFormatterOptions(WithBinaryFormat(BinaryHexdump), WithJSONPretty(true, 0), WithNumericLocale(true, "de-DE"))
+	if err := EncodeExpanded(buf, rs, opts, WithDecimalAlign(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeDecimalAlign(t *testing.T) {
+	t.Parallel()
+	rs := func() ResultSet {
+		return internal.New([]string{"name", "amount"}, [][]any{
+			{"a", 1.5},
+			{"b", 123.456},
+			{"c", 42},
+			{"d", "n/a"},
+		})
+	}
+	exp := ` name | amount  
+------+---------
+ a    |   1.5   
+ b    | 123.456 
+ c    |  42     
+ d    | n/a 
+(4 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, rs(), WithDecimalAlign(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+	exp = `-[ RECORD 1 ]-----
+ name   | a 
+ amount |   1.5 
+-[ RECORD 2 ]-----
+ name   | b 
+ amount | 123.456 
+-[ RECORD 3 ]-----
+ name   | c 
+ amount |  42 
+-[ RECORD 4 ]-----
+ name   | d 
+ amount | n/a 
+`
+	buf.Reset()
+	if err := EncodeExpanded(buf, rs(), WithDecimalAlign(true)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+	exp = ` name | amount 
+------+--------
+ a    |   1,5  
+ b    |  12,25 
+(2 rows)
+`
+	buf.Reset()
+	rs2 := internal.New([]string{"name", "amount"}, [][]any{{"a", 1.5}, {"b", 12.25}})
+	if err := EncodeTable(buf, rs2, WithDecimalAlign(true), WithFormatterOptions(WithNumericLocale(true, "de-DE"))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeTableGroupByErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		opt Option
+		exp error
+	}{
+		{WithGroupBy("missing"), ErrGroupByColumnNotInResult},
+		{WithGroupBy("region", "missing"), ErrSubtotalColumnNotInResult},
+	}
+	for i, test := range tests {
+		if err := EncodeTable(io.Discard, groupRset(), test.opt); err != test.exp {
+			t.Errorf("test %d expected error %v, got: %v", i, test.exp, err)
+		}
+	}
+}
+
+func groupRset() *internal.RS {
+	return internal.New([]string{"region", "name", "qty"}, [][]any{
+		{"east", "a", 1},
+		{"east", "b", 2},
+		{"west", "c", 4},
+		{"west", "d", 1.5},
+	})
+}
+
+func TestEncodeTableMergeRepeated(t *testing.T) {
+	t.Parallel()
+	exp := `┌────────┬───────┬─────┐
+│ parent │ child │ qty │
+├────────┼───────┼─────┤
+│ p1     │ a     │   1 │
+│        ├───────┼─────┤
+│        │ b     │   2 │
+├────────┼───────┼─────┤
+│ p2     │ b     │   4 │
+│        │       ├─────┤
+│        │       │   5 │
+└────────┴───────┴─────┘
+(4 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, repeatedRset(), WithBorder(2), WithLineStyle(UnicodeLineStyle()), WithMergeRepeated("parent", "child")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeTableGroupByMergeRepeated(t *testing.T) {
+	t.Parallel()
+	exp := `┌────────┬───────┬─────┐
+│ parent │ child │ qty │
+├────────┼───────┼─────┤
+│ p1     │ a     │   1 │
+│        ├───────┼─────┤
+│        │ b     │   2 │
+├────────┼───────┼─────┤
+│ p2     │ b     │   4 │
+│        │       ├─────┤
+│        │       │   5 │
+└────────┴───────┴─────┘
+(4 rows)
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeTable(buf, repeatedRset(), WithBorder(2), WithLineStyle(UnicodeLineStyle()), WithGroupBy("parent"), WithMergeRepeated("parent", "child")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeHTMLSuppressRepeated(t *testing.T) {
+	t.Parallel()
+	exp := `<table>
+  <caption></caption>
+  <thead>
+    <tr>
+      <th align="left">parent</th>
+      <th align="left">child</th>
+      <th align="left">qty</th>
+    </tr>
+  </thead>
+  <tbody>
+    <tr>
+      <td align="left" rowspan="2">p1</td>
+      <td align="left">a</td>
+      <td align="right">1</td>
+    </tr>
+    <tr>
+      <td align="left">b</td>
+      <td align="right">2</td>
+    </tr>
+    <tr>
+      <td align="left" rowspan="2">p2</td>
+      <td align="left" rowspan="2">b</td>
+      <td align="right">4</td>
+    </tr>
+    <tr>
+      <td align="right">5</td>
+    </tr>
+  </tbody>
+</table>
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeHTML(buf, repeatedRset(), WithSuppressRepeated("parent", "child")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeLaTeXSuppressRepeated(t *testing.T) {
+	t.Parallel()
+	exp := `\begin{center}
+a \& b\_c
+\end{center}
+
+\begin{tabular}{l | l | r}
+\textit{parent} & \textit{child} & \textit{qty} \\
+\hline
+p1 & a & 1 \\
+ & b & 2 \\
+p2 & b & 4 \\
+ &  & 5 \\
+\end{tabular}
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeLaTeX(buf, repeatedRset(), WithTitle("a & b_c"), WithSuppressRepeated("parent", "child")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func repeatedRset() *internal.RS {
+	return internal.New([]string{"parent", "child", "qty"}, [][]any{
+		{"p1", "a", 1},
+		{"p1", "b", 2},
+		{"p2", "b", 4},
+		{"p2", "b", 5},
+	})
+}
+
+func TestEncodeTableHeaderGroups(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		border int
+		exp    string
+	}{
+		{0, `       2024 year 2025 
+       ───────── ─────
+region Q1   Q2   Q1 Q2 
+────── ── ────── ── ──
+east    1      2  3  4 
+west   10     20 30 40 
+(2 rows)
+`},
+		{1, `        │ 2024 year │  2025 
+        ├────┬──────┼────┬────
+ region │ Q1 │  Q2  │ Q1 │ Q2 
+────────┼────┼──────┼────┼────
+ east   │  1 │    2 │  3 │  4 
+ west   │ 10 │   20 │ 30 │ 40 
+(2 rows)
+`},
+		{2, `┌────────┬───────────┬─────────┐
+│        │ 2024 year │  2025   │
+│        ├────┬──────┼────┬────┤
+│ region │ Q1 │  Q2  │ Q1 │ Q2 │
+├────────┼────┼──────┼────┼────┤
+│ east   │  1 │    2 │  3 │  4 │
+│ west   │ 10 │   20 │ 30 │ 40 │
+└────────┴────┴──────┴────┴────┘
+(2 rows)
+`},
+	}
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := EncodeTable(buf, headerGroupsRset(), WithBorder(test.border), WithLineStyle(UnicodeLineStyle()), WithHeaderGroups(
+			HeaderGroup{Span: 1},
+			HeaderGroup{Name: "2024 year", Span: 2},
+			HeaderGroup{Name: "2025", Span: 2},
+		)); err != nil {
+			t.Fatalf("border %d expected no error, got: %v", test.border, err)
+		}
+		if actual := buf.String(); actual != test.exp {
+			t.Errorf("border %d expected:\n%q\n---\ngot:\n%q", test.border, test.exp, actual)
+		}
+	}
+	if err := EncodeTable(io.Discard, headerGroupsRset(), WithHeaderGroups(HeaderGroup{Name: "a", Span: 6})); err != ErrInvalidHeaderGroups {
+		t.Errorf("expected error %v, got: %v", ErrInvalidHeaderGroups, err)
+	}
+}
+
+func TestEncodeHTMLHeaderGroups(t *testing.T) {
+	t.Parallel()
+	exp := `<table>
+  <caption></caption>
+  <thead>
+    <tr>
+      <th align="left" rowspan="2">region</th>
+      <th align="left" colspan="2">2024</th>
+      <th align="left" colspan="2">2025</th>
+    </tr>
+    <tr>
+      <th align="left">Q1</th>
+      <th align="left">Q2</th>
+      <th align="left">Q1</th>
+      <th align="left">Q2</th>
+    </tr>
+  </thead>
+  <tbody>
+    <tr>
+      <td align="left">east</td>
+      <td align="right">1</td>
+      <td align="right">2</td>
+      <td align="right">3</td>
+      <td align="right">4</td>
+    </tr>
+    <tr>
+      <td align="left">west</td>
+      <td align="right">10</td>
+      <td align="right">20</td>
+      <td align="right">30</td>
+      <td align="right">40</td>
+    </tr>
+  </tbody>
+</table>
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeHTML(buf, headerGroupsRset(), WithHeaderGroups(
+		HeaderGroup{Span: 1},
+		HeaderGroup{Name: "2024", Span: 2},
+		HeaderGroup{Name: "2025", Span: 2},
+	)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func TestEncodeLaTeXHeaderGroups(t *testing.T) {
+	t.Parallel()
+	exp := `\begin{tabular}{l | r | r | r | r}
+ & \multicolumn{2}{c |}{\textit{2024 year}} & \multicolumn{2}{c}{\textit{2025}} \\
+\cline{2-3} \cline{4-5}
+\textit{region} & \textit{Q1} & \textit{Q2} & \textit{Q1} & \textit{Q2} \\
+\hline
+east & 1 & 2 & 3 & 4 \\
+west & 10 & 20 & 30 & 40 \\
+\end{tabular}
+`
+	buf := new(bytes.Buffer)
+	if err := EncodeLaTeX(buf, headerGroupsRset(), WithHeaderGroups(
+		HeaderGroup{Span: 1},
+		HeaderGroup{Name: "2024 year", Span: 2},
+		HeaderGroup{Name: "2025", Span: 2},
+	)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if actual := buf.String(); actual != exp {
+		t.Errorf("expected:\n%q\n---\ngot:\n%q", exp, actual)
+	}
+}
+
+func headerGroupsRset() *internal.RS {
+	return internal.New([]string{"region", "Q1", "Q2", "Q1", "Q2"}, [][]any{
+		{"east", 1, 2, 3, 4},
+		{"west", 10, 20, 30, 40},
+	})
+}
@@ -11,7 +11,14 @@ import (
 type Template struct {
 	Attributes string
 	Headers    []*Value
-	Rows       [][]*Value
+	// Spans are the header groups spanning the headers, or nil when there are
+	// no header groups.
+	Spans []HeaderSpan
+	Rows  [][]*Value
+	// RowSpans are the number of rows spanned by each cell in Rows when
+	// repeated values are suppressed, where 0 indicates the cell is merged
+	// with a cell above. Nil when repeated values are not suppressed.
+	RowSpans   [][]int
 	SkipHeader bool
 	Title      *Value
 }
@@ -20,14 +27,18 @@ type Template struct {
 func WriteHTMLTo(w io.Writer, tpl *Template) error {
 	// {{ $headers := .Headers }}{{ $rows := .Rows }}<table{{ .Attributes | attr }}>
 	//   <caption>{{ .Title }}</caption>
-	//   <thead>
+	//   <thead>{{ if .Spans }}
+	//     <tr>{{ range $i, $s := .Spans }}{{ if $s.Value }}
+	//       <th align="{{ $s.Value.Align.String | toLower }}" colspan="{{ $s.Span }}">{{ $s.Value }}</th>{{ else }}
+	//       <th align="{{ (header $s).Align.String | toLower }}" rowspan="2">{{ header $s }}</th>{{ end }}{{ end }}
+	//     </tr>{{ $headers = grouped .Spans $headers }}{{ end }}
 	//     <tr>{{ range $i, $h := $headers }}
 	//       <th align="{{ $h.Align.String | toLower }}">{{ $h }}</th>{{ end }}
 	//     </tr>
 	//   </thead>
 	//   <tbody>{{ range $i, $r := $rows }}
-	//     <tr>{{ range $j, $c := $r  }}
-	//       <td align="{{ $c.Align.String | toLower }}">{{ $c }}</td>{{ end }}
+	//     <tr>{{ range $j, $c := $r }}{{ $n := rowspan $i $j }}{{ if $n }}
+	//       <td align="{{ $c.Align.String | toLower }}"{{ if gt $n 1 }} rowspan="{{ $n }}"{{ end }}>{{ $c }}</td>{{ end }}{{ end }}
 	//     </tr>{{ end }}
 	//   </tbody>
 	// </table>
@@ -36,14 +47,41 @@ func WriteHTMLTo(w io.Writer, tpl *Template) error {
 		fmt.Fprint(w, "", tpl.Attributes)
 	}
 	fmt.Fprintf(w, ">\n  <caption>%s</caption>\n  <thead>\n    <tr>\n", tpl.Title)
-	for _, h := range tpl.Headers {
+	headers := tpl.Headers
+	if tpl.Spans != nil {
+		// write header groups, with ungrouped headers spanning both rows
+		headers = nil
+		var i int
+		for _, span := range tpl.Spans {
+			switch h := span.Value; {
+			case h == nil:
+				h = tpl.Headers[i]
+				fmt.Fprintf(w, "      <th align=%q rowspan=\"2\">%s</th>\n", strings.ToLower(h.Align.String()), html.EscapeString(h.String()))
+			default:
+				fmt.Fprintf(w, "      <th align=%q colspan=\"%d\">%s</th>\n", strings.ToLower(h.Align.String()), span.Span, html.EscapeString(h.String()))
+				headers = append(headers, tpl.Headers[i:i+span.Span]...)
+			}
+			i += span.Span
+		}
+		fmt.Fprint(w, "    </tr>\n    <tr>\n")
+	}
+	for _, h := range headers {
 		fmt.Fprintf(w, "      <th align=%q>%s</th>\n", strings.ToLower(h.Align.String()), html.EscapeString(h.String()))
 	}
 	fmt.Fprint(w, "    </tr>\n  </thead>\n  <tbody>")
-	for _, r := range tpl.Rows {
+	for i, r := range tpl.Rows {
 		fmt.Fprint(w, "\n    <tr>")
-		for _, c := range r {
-			fmt.Fprintf(w, "\n      <td align=%q>%s</td>", strings.ToLower(c.Align.String()), html.EscapeString(c.String()))
+		for j, c := range r {
+			var rowspan string
+			if tpl.RowSpans != nil {
+				switch n := tpl.RowSpans[i][j]; {
+				case n == 0:
+					continue
+				case n > 1:
+					rowspan = fmt.Sprintf(" rowspan=\"%d\"", n)
+				}
+			}
+			fmt.Fprintf(w, "\n      <td align=%q%s>%s</td>", strings.ToLower(c.Align.String()), rowspan, html.EscapeString(c.String()))
 		}
 		fmt.Fprint(w, "\n    </tr>")
 	}
@@ -77,6 +115,103 @@ func WriteAsciidocTo(w io.Writer, tpl *Template) error {
 	return nil
 }
 
+// WriteLaTeXTo writes simple LaTeX output to the writer.
+func WriteLaTeXTo(w io.Writer, tpl *Template) error {
+	// {{ if .Title.Buf }}\begin{center}
+	// {{ .Title | latex }}
+	// \end{center}
+	//
+	// {{ end }}\begin{tabular}{ {{- .Rows | columns -}} }{{ if and .Spans (not .SkipHeader) }}
+	// {{ range $i, $s := .Spans }}{{ if $i }} & {{ end }}{{ if $s.Value }}\multicolumn{ {{- $s.Span -}} }{c}{\textit{ {{- $s.Value | latex -}} }}{{ end }}{{ end }} \\
+	// {{ range $s := .Spans }}{{ if $s.Value }}\cline{ {{- start $s }}-{{ end $s -}} }{{ end }}{{ end }}{{ end }}{{ if not .SkipHeader }}
+	// {{ range $i, $h := .Headers }}{{ if $i }} & {{ end }}\textit{ {{- $h | latex -}} }{{ end }} \\
+	// \hline{{ end }}{{ range $i, $r := .Rows }}
+	// {{ range $j, $c := $r }}{{ if $j }} & {{ end }}{{ if rowspan $i $j }}{{ $c | latex }}{{ end }}{{ end }} \\{{ end }}
+	// \end{tabular}
+	if s := tpl.Title.String(); s != "" {
+		fmt.Fprintf(w, "\\begin{center}\n%s\n\\end{center}\n\n", latexEscaper.Replace(s))
+	}
+	// column alignments, using the first row's alignments
+	n := len(tpl.Headers)
+	aligns := make([]string, n)
+	for j := range aligns {
+		a := AlignLeft
+		if len(tpl.Rows) != 0 && j < len(tpl.Rows[0]) {
+			a = tpl.Rows[0][j].Align
+		}
+		aligns[j] = latexAlign(a)
+	}
+	fmt.Fprintf(w, "\\begin{tabular}{%s}", strings.Join(aligns, " | "))
+	if tpl.Spans != nil && !tpl.SkipHeader {
+		// write header groups, with a rule below each group
+		var cells, rules []string
+		var i int
+		for _, span := range tpl.Spans {
+			switch h := span.Value; {
+			case h == nil:
+				cells = append(cells, "")
+			default:
+				align := "c"
+				if i+span.Span != n {
+					align += " |"
+				}
+				cells = append(cells, fmt.Sprintf("\\multicolumn{%d}{%s}{\\textit{%s}}", span.Span, align, latexEscaper.Replace(h.String())))
+				rules = append(rules, fmt.Sprintf("\\cline{%d-%d}", i+1, i+span.Span))
+			}
+			i += span.Span
+		}
+		fmt.Fprintf(w, "\n%s \\\\\n%s", strings.Join(cells, " & "), strings.Join(rules, " "))
+	}
+	if !tpl.SkipHeader {
+		cells := make([]string, len(tpl.Headers))
+		for j, h := range tpl.Headers {
+			cells[j] = fmt.Sprintf("\\textit{%s}", latexEscaper.Replace(h.String()))
+		}
+		fmt.Fprintf(w, "\n%s \\\\\n\\hline", strings.Join(cells, " & "))
+	}
+	for i, r := range tpl.Rows {
+		cells := make([]string, len(r))
+		for j, c := range r {
+			// leave merged cells blank
+			if tpl.RowSpans == nil || tpl.RowSpans[i][j] != 0 {
+				cells[j] = latexEscaper.Replace(c.String())
+			}
+		}
+		fmt.Fprintf(w, "\n%s \\\\", strings.Join(cells, " & "))
+	}
+	fmt.Fprintln(w, "\n\\end{tabular}")
+	return nil
+}
+
+// latexEscaper escapes LaTeX special characters.
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`_`, `\_`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`^`, `\^{}`,
+	`~`, `\~{}`,
+	`<`, `\textless{}`,
+	`>`, `\textgreater{}`,
+	`|`, `\textbar{}`,
+	"\n", `\\`,
+)
+
+// latexAlign returns the LaTeX column specifier for the alignment.
+func latexAlign(a Align) string {
+	switch a {
+	case AlignRight:
+		return "r"
+	case AlignCenter:
+		return "c"
+	}
+	return "l"
+}
+
 // WriteVerticalTo writes simple vertical output to the writer.
 func WriteVerticalTo(w io.Writer, tpl *Template) error {
 	// {{ $headers := .Headers }}{{ range $i, $r := .Rows }}*************************** {{ inc $i }}. row ***************************{{ range $j, $c := $r }}
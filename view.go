@@ -1,10 +1,16 @@
 package tblfmt
 
 import (
+	"cmp"
+	"database/sql"
+	"fmt"
 	"slices"
-	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 // CrosstabView is a crosstab view for result sets.
@@ -27,20 +33,48 @@ type CrosstabView struct {
 	headerTransformer Transformer
 	// columnTypes is used to build column types for a result set.
 	columnTypes func(ResultSet, []any, int) error
-	// v is the vertical header column.
-	v string
+	// v are the vertical header columns.
+	v []string
 	// h is the horizontal header column.
 	h string
-	// d is the data column.
-	d string
+	// d are the data columns.
+	d []string
 	// s is the horizontal header sort column.
 	s string
-	// vmap is the map of vertical rows.
+	// pattern is the column name pattern used with multiple data columns.
+	pattern string
+	// hsort is the horizontal header sort order.
+	hsort SortOrder
+	// vsort is the vertical header sort order.
+	vsort SortOrder
+	// collator is the collator used to sort strings.
+	collator *collate.Collator
+	// agg is the aggregate for cells with multiple values.
+	agg Aggregate
+	// sep is the separator for concatenated values.
+	sep string
+	// rowTotals toggles a total column with the totals of each row.
+	rowTotals bool
+	// colTotals toggles a total row with the totals of each column.
+	colTotals bool
+	// totalLabel is the label of the total column and row.
+	totalLabel string
+	// vkeys are the vertical row keys.
 	vkeys []string
+	// vvals are the vertical header values for each vertical row key.
+	vvals map[string][]string
+	// vraw are the raw vertical header values for each vertical row key.
+	vraw map[string][]any
 	// hmap is the map of horizontal columns.
 	hkeys []hkey
 	// vals are the result values.
-	vals map[string]map[string]any
+	vals map[string]map[string]aggregators
+	// rtotals are the row totals.
+	rtotals map[string]aggregators
+	// ctotals are the column totals.
+	ctotals map[string]aggregators
+	// total is the grand total.
+	total aggregators
 	// pos is the index for the result.
 	pos int
 	// err is the last encountered error.
@@ -55,13 +89,16 @@ func NewCrosstabView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
 		empty: &Value{
 			Tabs: make([][][2]int, 1),
 		},
+		pattern:    "%s %s",
+		sep:        ", ",
+		totalLabel: "total",
 	}
 	for _, o := range opts {
 		if err := o.apply(view); err != nil {
 			return nil, err
 		}
 	}
-	if view.v != "" && view.h != "" && view.v == view.h {
+	if view.h != "" && slices.Contains(view.v, view.h) {
 		return nil, ErrCrosstabVerticalAndHorizontalColumnsMustNotBeSame
 	}
 	if err := view.build(); err != nil {
@@ -74,7 +111,15 @@ func NewCrosstabView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
 func (view *CrosstabView) build() error {
 	// reset
 	view.pos = -1
-	view.vals = make(map[string]map[string]any)
+	view.vvals = make(map[string][]string)
+	view.vraw = make(map[string][]any)
+	view.vals = make(map[string]map[string]aggregators)
+	view.rtotals = make(map[string]aggregators)
+	view.ctotals = make(map[string]aggregators)
+	view.total = nil
+	if len(view.v) == 0 {
+		view.v = []string{""}
+	}
 	// get columns
 	clen, cols, err := buildColNames(view.resultSet, view.headerTransformer)
 	switch {
@@ -82,19 +127,26 @@ func (view *CrosstabView) build() error {
 		return view.fail(err)
 	case clen < 3:
 		return view.fail(ErrCrosstabResultMustHaveAtLeast3Columns)
-	case clen > 3 && view.d == "":
+	case clen > len(view.v)+2 && len(view.d) == 0:
 		return view.fail(ErrCrosstabDataColumnMustBeSpecifiedWhenQueryReturnsMoreThanThreeColumns)
 	}
-	vindex := findIndex(cols, view.v, 0)
-	if vindex == -1 {
-		return view.fail(ErrCrosstabVerticalColumnNotInResult)
+	used := make(map[int]bool)
+	vindexes := make([]int, len(view.v))
+	for i, v := range view.v {
+		vindex := findIndex(cols, v, i)
+		if vindex == -1 {
+			return view.fail(ErrCrosstabVerticalColumnNotInResult)
+		}
+		view.v[i], vindexes[i], used[vindex] = cols[vindex], vindex, true
 	}
-	view.v = cols[vindex]
-	hindex := findIndex(cols, view.h, 1)
-	if hindex == -1 {
+	hindex := findIndex(cols, view.h, len(view.v))
+	switch {
+	case hindex == -1:
 		return view.fail(ErrCrosstabHorizontalColumnNotInResult)
+	case used[hindex]:
+		return view.fail(ErrCrosstabVerticalAndHorizontalColumnsMustNotBeSame)
 	}
-	view.h = cols[hindex]
+	view.h, used[hindex] = cols[hindex], true
 	// this complicated bit of code is used to find the 'unused' column for d
 	// (ie, when number of columns == 3, and v and h are specified)
 	//
@@ -103,23 +155,23 @@ func (view *CrosstabView) build() error {
 	//   " If colD is not specified, then there must be exactly three columns
 	//   in the query result, and the column that is neither colV nor colH is
 	//   taken to be colD."
-	ddef := 2
-	if view.d == "" {
-		used := map[int]bool{
-			vindex: true,
-			hindex: true,
-		}
-		for i := range 3 {
+	ddef := -1
+	if len(view.d) == 0 {
+		for i := range clen {
 			if !used[i] {
 				ddef = i
 			}
 		}
+		view.d = []string{""}
 	}
-	dindex := findIndex(cols, view.d, ddef)
-	if dindex == -1 {
-		return view.fail(ErrCrosstabDataColumnNotInResult)
+	dindexes := make([]int, len(view.d))
+	for i, d := range view.d {
+		dindex := findIndex(cols, d, ddef)
+		if dindex == -1 {
+			return view.fail(ErrCrosstabDataColumnNotInResult)
+		}
+		view.d[i], dindexes[i] = cols[dindex], dindex
 	}
-	view.d = cols[dindex]
 	sindex := -1
 	if view.s != "" {
 		if sidx := indexOf(cols, view.s); sidx != -1 {
@@ -129,6 +181,7 @@ func (view *CrosstabView) build() error {
 		}
 	}
 	// process results
+	n := len(vindexes)
 	for view.resultSet.Next() {
 		r, err := buildColumnTypes(view.resultSet, clen, view.columnTypes)
 		if err != nil {
@@ -138,33 +191,79 @@ func (view *CrosstabView) build() error {
 			return view.fail(err)
 		}
 		// raw format values
-		vals := []any{r[vindex], r[hindex]}
-		if sindex != -1 {
-			vals = append(vals, r[sindex])
+		vals := make([]any, 0, n+len(dindexes)+1)
+		for _, i := range vindexes {
+			vals = append(vals, r[i])
+		}
+		vals = append(vals, r[hindex])
+		for _, i := range dindexes {
+			vals = append(vals, r[i])
 		}
 		v, err := view.formatter.Format(vals)
 		if err != nil {
 			return view.fail(err)
 		}
-		var s *Value
+		row := crosstabRow{
+			v:  make([]any, n),
+			h:  deref(r[hindex]),
+			d:  make([]any, len(dindexes)),
+			vs: v[:n],
+			hs: v[n],
+			ds: v[n+1:],
+		}
+		for i, j := range vindexes {
+			row.v[i] = deref(r[j])
+		}
+		for i, j := range dindexes {
+			row.d[i] = deref(r[j])
+		}
 		if sindex != -1 {
-			s = v[2]
+			row.s = deref(r[sindex])
 		}
-		d := deref(r[dindex])
-		if err := view.add(d, v[0], v[1], s); err != nil {
+		if err := view.add(row); err != nil {
 			return view.fail(err)
 		}
 	}
 	if err := view.resultSet.Err(); err != nil {
 		return view.fail(err)
 	}
-	// sort
-	if sindex != -1 {
-		sort.Slice(view.hkeys, func(i, j int) bool {
-			return view.hkeys[i].s < view.hkeys[j].s
+	view.sort(sindex != -1)
+	return nil
+}
+
+// sort sorts the horizontal and vertical keys. When sorted by the sort
+// column, the horizontal keys are sorted ascending unless a sort order has
+// been set.
+func (view *CrosstabView) sort(bySortColumn bool) {
+	hsort := view.hsort
+	if hsort == SortNone && bySortColumn {
+		hsort = SortAsc
+	}
+	if hsort == SortNone && view.vsort == SortNone {
+		return
+	}
+	if view.collator == nil {
+		view.collator = collate.New(language.Und)
+	}
+	if hsort != SortNone {
+		slices.SortStableFunc(view.hkeys, func(a, b hkey) int {
+			if bySortColumn {
+				return hsort.apply(compareValues(a.s, b.s, view.collator))
+			}
+			return hsort.apply(compareValues(a.raw, b.raw, view.collator))
+		})
+	}
+	if view.vsort != SortNone {
+		slices.SortStableFunc(view.vkeys, func(a, b string) int {
+			x, y := view.vraw[a], view.vraw[b]
+			for i := range x {
+				if c := compareValues(x[i], y[i], view.collator); c != 0 {
+					return view.vsort.apply(c)
+				}
+			}
+			return 0
 		})
 	}
-	return nil
 }
 
 // fail sets the internal error to the passed error and returns it.
@@ -173,59 +272,157 @@ func (view *CrosstabView) fail(err error) error {
 	return err
 }
 
-// add processes and adds a val.
-func (view *CrosstabView) add(d any, v, h, s *Value) error {
-	if v == nil {
-		v = view.empty
-	}
+// crosstabRow is a row of the wrapped result set.
+type crosstabRow struct {
+	// v, h, d and s are the vertical, horizontal, data and sort values.
+	v []any
+	h any
+	d []any
+	s any
+	// vs, hs and ds are the formatted vertical, horizontal and data values.
+	vs []*Value
+	hs *Value
+	ds []*Value
+}
+
+// add processes and adds a row.
+func (view *CrosstabView) add(row crosstabRow) error {
+	h := row.hs
 	if h == nil {
 		h = view.empty
 	}
-	// determine sort value
-	var sval int
-	if s != nil {
-		var err error
-		sval, err = strconv.Atoi(s.String())
-		if err != nil {
-			return ErrCrosstabHorizontalSortColumnIsNotANumber
+	// add v and h keys
+	vvals := make([]string, len(row.vs))
+	for i, v := range row.vs {
+		if v != nil {
+			vvals[i] = v.String()
 		}
 	}
-	// add v and h keys
-	vk, hk := v.String(), h.String()
+	vk, hk := strings.Join(vvals, "\x00"), h.String()
+	if (view.rowTotals && hk == view.totalLabel) || (view.colTotals && vvals[0] == view.totalLabel) {
+		return ErrCrosstabTotalLabelIsAValue
+	}
+	if _, ok := view.vvals[vk]; !ok {
+		view.vvals[vk], view.vraw[vk] = vvals, row.v
+	}
 	view.vkeys = vkeyAppend(view.vkeys, vk)
-	view.hkeys = hkeyAppend(view.hkeys, hkey{v: hk, s: sval})
+	view.hkeys = hkeyAppend(view.hkeys, hkey{v: hk, raw: row.h, s: row.s})
+	d, ds := row.d, row.ds
 	// store
 	if _, ok := view.vals[vk]; !ok {
-		view.vals[vk] = make(map[string]any)
+		view.vals[vk] = make(map[string]aggregators)
 	}
-	if _, ok := view.vals[vk][hk]; ok {
+	a, ok := view.vals[vk][hk]
+	switch {
+	case ok && view.agg == AggregateNone:
 		return ErrCrosstabDuplicateVerticalAndHorizontalValue
+	case !ok:
+		a = view.newAggregators(false)
+		view.vals[vk][hk] = a
 	}
-	view.vals[vk][hk] = d
+	strs := make([]string, len(ds))
+	for i := range ds {
+		if ds[i] != nil {
+			strs[i] = ds[i].String()
+		}
+	}
+	a.add(d, strs)
+	// totals
+	if !view.rowTotals && !view.colTotals {
+		return nil
+	}
+	if _, ok := view.rtotals[vk]; !ok {
+		view.rtotals[vk] = view.newAggregators(true)
+	}
+	if _, ok := view.ctotals[hk]; !ok {
+		view.ctotals[hk] = view.newAggregators(true)
+	}
+	if view.total == nil {
+		view.total = view.newAggregators(true)
+	}
+	view.rtotals[vk].add(d, strs)
+	view.ctotals[hk].add(d, strs)
+	view.total.add(d, strs)
 	return nil
 }
 
+// newAggregators creates new aggregators for each data column of a cell or a
+// total. Totals use the sum aggregate when no aggregate has been set.
+func (view *CrosstabView) newAggregators(total bool) aggregators {
+	agg := view.agg
+	if total && agg == AggregateNone {
+		agg = AggregateSum
+	}
+	a := make(aggregators, len(view.d))
+	for i := range a {
+		a[i] = &aggregator{
+			agg: agg,
+			sep: view.sep,
+		}
+	}
+	return a
+}
+
+// appendColNames appends the column names for the horizontal value h to cols.
+// When there are multiple data columns, the names are built using the column
+// pattern.
+func (view *CrosstabView) appendColNames(cols []string, h string) []string {
+	if len(view.d) == 1 {
+		return append(cols, h)
+	}
+	for _, d := range view.d {
+		cols = append(cols, fmt.Sprintf(view.pattern, h, d))
+	}
+	return cols
+}
+
 // Next satisfies the ResultSet interface.
 func (view *CrosstabView) Next() bool {
 	if view.err != nil {
 		return false
 	}
 	view.pos++
-	return view.pos < len(view.vkeys)
+	n := len(view.vkeys)
+	if view.colTotals {
+		n++
+	}
+	return view.pos < n
 }
 
 // Scan satisfies the ResultSet interface.
 func (view *CrosstabView) Scan(v ...any) error {
-	vkey := view.vkeys[view.pos]
-	if len(v) > 0 {
-		*(v[0].(*any)) = vkey
-	}
-	row := view.vals[vkey]
-	for i := 0; i < len(view.hkeys) && i < len(v)-1; i++ {
-		if z, ok := row[view.hkeys[i].v]; ok {
-			*(v[i+1].(*any)) = z
-		} else {
-			*(v[i+1].(*any)) = nil
+	// total row
+	row, rtotal := view.ctotals, view.total
+	var vvals []string
+	if view.pos < len(view.vkeys) {
+		vkey := view.vkeys[view.pos]
+		row, rtotal, vvals = view.vals[vkey], view.rtotals[vkey], view.vvals[vkey]
+	}
+	var i int
+	set := func(z any) {
+		if i < len(v) {
+			*(v[i].(*any)) = z
+		}
+		i++
+	}
+	for j := range view.v {
+		switch {
+		case vvals != nil:
+			set(vvals[j])
+		case j == 0:
+			set(view.totalLabel)
+		default:
+			set(nil)
+		}
+	}
+	for _, h := range view.hkeys {
+		for j := range view.d {
+			set(row[h.v].value(j))
+		}
+	}
+	if view.rowTotals {
+		for j := range view.d {
+			set(rtotal.value(j))
 		}
 	}
 	return nil
@@ -236,10 +433,12 @@ func (view *CrosstabView) Columns() ([]string, error) {
 	if view.err != nil {
 		return nil, view.err
 	}
-	cols := make([]string, len(view.hkeys)+1)
-	cols[0] = view.v
-	for i := range len(view.hkeys) {
-		cols[i+1] = view.hkeys[i].v
+	cols := slices.Clone(view.v)
+	for _, h := range view.hkeys {
+		cols = view.appendColNames(cols, h.v)
+	}
+	if view.rowTotals {
+		cols = view.appendColNames(cols, view.totalLabel)
 	}
 	return cols, nil
 }
@@ -259,6 +458,1003 @@ func (view *CrosstabView) NextResultSet() bool {
 	return false
 }
 
+// SortOrder is a sort order.
+type SortOrder int
+
+// Sort orders.
+const (
+	// SortNone does not sort, leaving values in the order first seen.
+	SortNone SortOrder = iota
+	// SortAsc sorts ascending.
+	SortAsc
+	// SortDesc sorts descending.
+	SortDesc
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (order SortOrder) String() string {
+	switch order {
+	case SortAsc:
+		return "asc"
+	case SortDesc:
+		return "desc"
+	}
+	return "none"
+}
+
+// apply applies the sort order to the result of a comparison.
+func (order SortOrder) apply(c int) int {
+	if order == SortDesc {
+		return -c
+	}
+	return c
+}
+
+// Aggregate is a crosstab aggregate, used to combine the values of a crosstab
+// cell when the result set has more than one row for the vertical and
+// horizontal values.
+type Aggregate int
+
+// Aggregates.
+const (
+	// AggregateNone does not aggregate values, and causes the crosstab view to
+	// fail when a cell has more than one value.
+	AggregateNone Aggregate = iota
+	// AggregateSum is the sum of numeric values.
+	AggregateSum
+	// AggregateCount is the count of non-NULL values.
+	AggregateCount
+	// AggregateMin is the minimum value.
+	AggregateMin
+	// AggregateMax is the maximum value.
+	AggregateMax
+	// AggregateAvg is the average of numeric values.
+	AggregateAvg
+	// AggregateFirst is the first value.
+	AggregateFirst
+	// AggregateLast is the last value.
+	AggregateLast
+	// AggregateConcat is the formatted values joined by a separator. See
+	// [WithConcatSeparator].
+	AggregateConcat
+)
+
+// String satisfies the [fmt.Stringer] interface.
+func (agg Aggregate) String() string {
+	switch agg {
+	case AggregateSum:
+		return "sum"
+	case AggregateCount:
+		return "count"
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	case AggregateAvg:
+		return "avg"
+	case AggregateFirst:
+		return "first"
+	case AggregateLast:
+		return "last"
+	case AggregateConcat:
+		return "concat"
+	}
+	return "none"
+}
+
+// aggregator accumulates the values of a crosstab cell or total.
+type aggregator struct {
+	// agg is the aggregate.
+	agg Aggregate
+	// sep is the separator for concatenated values.
+	sep string
+	// set indicates a value has been added.
+	set bool
+	// n is the count of non-NULL values.
+	n int64
+	// nums is the count of numeric values.
+	nums int64
+	// sum is the sum of numeric values.
+	sum subtotal
+	// first, last, min, and max are the first, last, minimum and maximum
+	// values.
+	first, last, min, max any
+	// strs are the formatted non-NULL values.
+	strs []string
+}
+
+// add adds a value and its formatted string to the aggregator.
+func (a *aggregator) add(v any, s string) {
+	if !a.set {
+		a.first, a.set = v, true
+	}
+	a.last = v
+	if unwrapValue(v) == nil {
+		return
+	}
+	a.n++
+	if z := toNumber(v); z != nil {
+		a.sum.add(z)
+		a.nums++
+	}
+	if a.min == nil || compareValues(v, a.min, nil) < 0 {
+		a.min = v
+	}
+	if a.max == nil || compareValues(v, a.max, nil) > 0 {
+		a.max = v
+	}
+	if a.agg == AggregateConcat {
+		a.strs = append(a.strs, s)
+	}
+}
+
+// value returns the aggregated value. Returns nil for a nil aggregator.
+func (a *aggregator) value() any {
+	switch {
+	case a == nil:
+		return nil
+	case a.agg == AggregateSum:
+		return a.sum.value()
+	case a.agg == AggregateCount:
+		return a.n
+	case a.agg == AggregateMin:
+		return a.min
+	case a.agg == AggregateMax:
+		return a.max
+	case a.agg == AggregateAvg:
+		if a.nums == 0 {
+			return nil
+		}
+		return a.sum.f / float64(a.nums)
+	case a.agg == AggregateLast:
+		return a.last
+	case a.agg == AggregateConcat:
+		if len(a.strs) == 0 {
+			return nil
+		}
+		return strings.Join(a.strs, a.sep)
+	}
+	return a.first
+}
+
+// aggregators are the aggregators for each data column of a crosstab cell or
+// total.
+type aggregators []*aggregator
+
+// add adds the values and their formatted strings to the aggregators.
+func (a aggregators) add(vals []any, strs []string) {
+	for i := range a {
+		a[i].add(vals[i], strs[i])
+	}
+}
+
+// value returns the aggregated value for data column i.
+func (a aggregators) value(i int) any {
+	if i < len(a) {
+		return a[i].value()
+	}
+	return nil
+}
+
+// compareValues compares a and b, comparing numeric values (including numeric
+// strings), times and bools by value, and all other values by their string
+// representation using the collator (when not nil). Numeric values sort before
+// times, times before bools, and bools before all other values, so that the
+// comparison is a total order for columns of mixed values. Equal numeric
+// values are compared by their string representation (ie, "01" and "1" are
+// not equal). NULL values sort after all other values.
+func compareValues(a, b any, c *collate.Collator) int {
+	a, b = unwrapValue(a), unwrapValue(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	x, y := toNumber(a), toNumber(b)
+	if r, s := rankOf(a, x), rankOf(b, y); r != s {
+		return cmp.Compare(r, s)
+	}
+	switch z := a.(type) {
+	case time.Time:
+		return z.Compare(b.(time.Time))
+	case bool:
+		switch w := b.(bool); {
+		case z == w:
+			return 0
+		case z:
+			return 1
+		}
+		return -1
+	}
+	if x != nil {
+		xi, xok := x.(int64)
+		yi, yok := y.(int64)
+		switch {
+		case xok && yok:
+			if n := cmp.Compare(xi, yi); n != 0 {
+				return n
+			}
+		default:
+			if n := cmp.Compare(toFloat(x), toFloat(y)); n != 0 {
+				return n
+			}
+		}
+		return strings.Compare(toString(a), toString(b))
+	}
+	s, t := toString(a), toString(b)
+	if c != nil {
+		return c.CompareString(s, t)
+	}
+	return strings.Compare(s, t)
+}
+
+// rankOf returns the sort rank of v for compareValues, where n is the numeric
+// value of v.
+func rankOf(v, n any) int {
+	if n != nil {
+		return 0
+	}
+	switch v.(type) {
+	case time.Time:
+		return 1
+	case bool:
+		return 2
+	}
+	return 3
+}
+
+// toString returns the string representation of v.
+func toString(v any) string {
+	switch z := v.(type) {
+	case string:
+		return z
+	case []byte:
+		return string(z)
+	}
+	return fmt.Sprint(v)
+}
+
+// toFloat converts an int64 or float64 to a float64.
+func toFloat(v any) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
+
+// TransposeView is a transpose view for result sets, swapping the rows and
+// columns of a result set.
+//
+// The column names of the wrapped result set become the first column, and the
+// row numbers (or values of a key column, when specified) become the column
+// names. The key column is not included in the rows.
+//
+// As with the crosstab view, NextResultSet always returns false.
+type TransposeView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// formatter is the formatter.
+	formatter Formatter
+	// headerTransformer is the column header transformer.
+	headerTransformer Transformer
+	// columnTypes is used to build column types for a result set.
+	columnTypes func(ResultSet, []any, int) error
+	// k is the key column.
+	k string
+	// cols are the result columns.
+	cols []string
+	// rows are the result rows.
+	rows [][]any
+	// pos is the index for the result.
+	pos int
+	// err is the last encountered error.
+	err error
+}
+
+// NewTransposeView creates a new transpose view. Use [WithParams] to specify
+// the key column.
+func NewTransposeView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
+	view := &TransposeView{
+		resultSet: resultSet,
+		formatter: NewEscapeFormatter(WithIsRaw(true, 0, 0)),
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// build builds the transpose view.
+func (view *TransposeView) build() error {
+	// reset
+	view.pos = -1
+	// get columns
+	clen, cols, err := buildColNames(view.resultSet, view.headerTransformer)
+	if err != nil {
+		return view.fail(err)
+	}
+	kindex := -1
+	if view.k != "" {
+		if kindex = indexOf(cols, view.k); kindex == -1 {
+			return view.fail(ErrTransposeKeyColumnNotInResult)
+		}
+	}
+	view.cols = []string{"column"}
+	if kindex != -1 {
+		view.cols[0] = cols[kindex]
+	}
+	view.rows = make([][]any, 0, clen)
+	for i := range clen {
+		if i != kindex {
+			view.rows = append(view.rows, []any{cols[i]})
+		}
+	}
+	// process results
+	for n := 1; view.resultSet.Next(); n++ {
+		r, err := buildColumnTypes(view.resultSet, clen, view.columnTypes)
+		if err != nil {
+			return view.fail(err)
+		}
+		if err := view.resultSet.Scan(r...); err != nil {
+			return view.fail(err)
+		}
+		// add column name
+		name := strconv.Itoa(n)
+		if kindex != -1 {
+			v, err := view.formatter.Format([]any{r[kindex]})
+			if err != nil {
+				return view.fail(err)
+			}
+			if name = ""; v[0] != nil {
+				name = v[0].String()
+			}
+		}
+		view.cols = append(view.cols, name)
+		// add values
+		var j int
+		for i := range clen {
+			if i != kindex {
+				view.rows[j] = append(view.rows[j], deref(r[i]))
+				j++
+			}
+		}
+	}
+	if err := view.resultSet.Err(); err != nil {
+		return view.fail(err)
+	}
+	return nil
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *TransposeView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// Next satisfies the ResultSet interface.
+func (view *TransposeView) Next() bool {
+	if view.err != nil {
+		return false
+	}
+	view.pos++
+	return view.pos < len(view.rows)
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *TransposeView) Scan(v ...any) error {
+	if view.pos < 0 || len(view.rows) <= view.pos {
+		return sql.ErrNoRows
+	}
+	row := view.rows[view.pos]
+	for i := 0; i < len(row) && i < len(v); i++ {
+		if err := assign(v[i], row[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *TransposeView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	return view.cols, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (view *TransposeView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *TransposeView) Err() error {
+	return view.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *TransposeView) NextResultSet() bool {
+	return false
+}
+
+// UnpivotView is an unpivot (melt) view for result sets, the inverse of the
+// crosstab view, turning the value columns of each row into separate rows.
+//
+// Each row of the wrapped result set produces a (id..., variable, value) row
+// for each value column, where the variable is the name of the value column.
+// Rows are produced lazily as the wrapped result set is read.
+type UnpivotView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// headerTransformer is the column header transformer.
+	headerTransformer Transformer
+	// columnTypes is used to build column types for a result set.
+	columnTypes func(ResultSet, []any, int) error
+	// ids are the id columns.
+	ids []string
+	// vals are the value columns.
+	vals []string
+	// variable is the name of the variable column.
+	variable string
+	// value is the name of the value column.
+	value string
+	// dropNulls toggles dropping rows with NULL values.
+	dropNulls bool
+	// clen is the column count of the wrapped result set.
+	clen int
+	// cols are the column names of the wrapped result set.
+	cols []string
+	// iindexes are the id column indexes.
+	iindexes []int
+	// vindexes are the value column indexes.
+	vindexes []int
+	// row is the current row of the wrapped result set.
+	row []any
+	// pos is the index of the current value column.
+	pos int
+	// err is the last encountered error.
+	err error
+}
+
+// NewUnpivotView creates a new unpivot view. Use [WithIDColumns] and
+// [WithValueColumns] to specify the columns.
+func NewUnpivotView(resultSet ResultSet, opts ...Option) (ResultSet, error) {
+	view := &UnpivotView{
+		resultSet: resultSet,
+		variable:  "variable",
+		value:     "value",
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// build builds the unpivot view for the current result set.
+func (view *UnpivotView) build() error {
+	// reset
+	view.row, view.pos = nil, 0
+	view.iindexes, view.vindexes = view.iindexes[:0], view.vindexes[:0]
+	// get columns
+	var err error
+	if view.clen, view.cols, err = buildColNames(view.resultSet, view.headerTransformer); err != nil {
+		return view.fail(err)
+	}
+	used := make(map[int]bool)
+	for _, s := range view.ids {
+		i := indexOf(view.cols, s)
+		if i == -1 {
+			return view.fail(ErrUnpivotIDColumnNotInResult)
+		}
+		view.iindexes, used[i] = append(view.iindexes, i), true
+	}
+	for _, s := range view.vals {
+		i := indexOf(view.cols, s)
+		if i == -1 {
+			return view.fail(ErrUnpivotValueColumnNotInResult)
+		}
+		view.vindexes = append(view.vindexes, i)
+	}
+	if len(view.vals) == 0 {
+		for i := range view.clen {
+			if !used[i] {
+				view.vindexes = append(view.vindexes, i)
+			}
+		}
+	}
+	return nil
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *UnpivotView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// Next satisfies the ResultSet interface.
+func (view *UnpivotView) Next() bool {
+	if view.err != nil || len(view.vindexes) == 0 {
+		return false
+	}
+	for {
+		if view.pos++; view.row == nil || view.pos >= len(view.vindexes) {
+			if !view.resultSet.Next() {
+				view.row, view.err = nil, view.resultSet.Err()
+				return false
+			}
+			r, err := buildColumnTypes(view.resultSet, view.clen, view.columnTypes)
+			if err != nil {
+				view.fail(err)
+				return false
+			}
+			if err := view.resultSet.Scan(r...); err != nil {
+				view.fail(err)
+				return false
+			}
+			view.row, view.pos = r, 0
+		}
+		if !view.dropNulls || unwrapValue(view.row[view.vindexes[view.pos]]) != nil {
+			return true
+		}
+	}
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *UnpivotView) Scan(v ...any) error {
+	if view.row == nil || view.pos < 0 || len(view.vindexes) <= view.pos {
+		return sql.ErrNoRows
+	}
+	vals := make([]any, 0, len(view.iindexes)+2)
+	for _, i := range view.iindexes {
+		vals = append(vals, deref(view.row[i]))
+	}
+	j := view.vindexes[view.pos]
+	vals = append(vals, view.cols[j], deref(view.row[j]))
+	for i := 0; i < len(vals) && i < len(v); i++ {
+		if err := assign(v[i], vals[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *UnpivotView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	cols := make([]string, 0, len(view.iindexes)+2)
+	for _, i := range view.iindexes {
+		cols = append(cols, view.cols[i])
+	}
+	return append(cols, view.variable, view.value), nil
+}
+
+// Close satisfies the ResultSet interface.
+func (view *UnpivotView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *UnpivotView) Err() error {
+	return view.err
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *UnpivotView) NextResultSet() bool {
+	if view.err != nil || !view.resultSet.NextResultSet() {
+		return false
+	}
+	_ = view.build()
+	return true
+}
+
+// ProjectView is a view that selects, reorders or drops the columns of a
+// result set. See [NewSelectView] and [NewDropView].
+type ProjectView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// columns are the selected or dropped columns.
+	columns []string
+	// drop toggles dropping the columns instead of selecting them.
+	drop bool
+	// clen is the column count of the wrapped result set.
+	clen int
+	// cols are the column names of the wrapped result set.
+	cols []string
+	// indexes are the indexes of the columns in the wrapped result set.
+	indexes []int
+	// err is the last encountered error.
+	err error
+}
+
+// NewSelectView creates a view selecting the columns of a result set, in the
+// order specified. Columns can be specified by name or by 1-based position.
+func NewSelectView(resultSet ResultSet, columns ...string) (*ProjectView, error) {
+	view := &ProjectView{
+		resultSet: resultSet,
+		columns:   columns,
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// NewDropView creates a view dropping the columns of a result set. Columns
+// can be specified by name or by 1-based position.
+func NewDropView(resultSet ResultSet, columns ...string) (*ProjectView, error) {
+	view := &ProjectView{
+		resultSet: resultSet,
+		columns:   columns,
+		drop:      true,
+	}
+	if err := view.build(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// build builds the column indexes for the current result set.
+func (view *ProjectView) build() error {
+	var err error
+	if view.clen, view.cols, err = buildColNames(view.resultSet, nil); err != nil {
+		return view.fail(err)
+	}
+	view.indexes = view.indexes[:0]
+	dropped := make(map[int]bool)
+	for _, s := range view.columns {
+		i := indexOf(view.cols, s)
+		if i == -1 {
+			return view.fail(ErrColumnNotInResult)
+		}
+		if view.drop {
+			dropped[i] = true
+		} else {
+			view.indexes = append(view.indexes, i)
+		}
+	}
+	if view.drop {
+		for i := range view.clen {
+			if !dropped[i] {
+				view.indexes = append(view.indexes, i)
+			}
+		}
+	}
+	return nil
+}
+
+// fail sets the internal error to the passed error and returns it.
+func (view *ProjectView) fail(err error) error {
+	view.err = err
+	return err
+}
+
+// Next satisfies the ResultSet interface.
+func (view *ProjectView) Next() bool {
+	return view.err == nil && view.resultSet.Next()
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *ProjectView) Scan(v ...any) error {
+	r := make([]any, view.clen)
+	for i, j := range view.indexes {
+		if i < len(v) && r[j] == nil {
+			r[j] = v[i]
+		}
+	}
+	for i := range r {
+		if r[i] == nil {
+			r[i] = new(any)
+		}
+	}
+	if err := view.resultSet.Scan(r...); err != nil {
+		return err
+	}
+	// assign columns selected more than once
+	for i, j := range view.indexes {
+		if i < len(v) && r[j] != v[i] {
+			if err := assign(v[i], deref(r[j]), -1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *ProjectView) Columns() ([]string, error) {
+	if view.err != nil {
+		return nil, view.err
+	}
+	cols := make([]string, len(view.indexes))
+	for i, j := range view.indexes {
+		cols[i] = view.cols[j]
+	}
+	return cols, nil
+}
+
+// ColumnTypes returns the column types of the wrapped result set's columns.
+func (view *ProjectView) ColumnTypes() ([]*sql.ColumnType, error) {
+	types, err := resultSetColumns(view.resultSet, view.clen)
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]*sql.ColumnType, len(view.indexes))
+	for i, j := range view.indexes {
+		cols[i] = types[j]
+	}
+	return cols, nil
+}
+
+// Close satisfies the ResultSet interface.
+func (view *ProjectView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *ProjectView) Err() error {
+	if view.err != nil {
+		return view.err
+	}
+	return view.resultSet.Err()
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *ProjectView) NextResultSet() bool {
+	if view.err != nil || !view.resultSet.NextResultSet() {
+		return false
+	}
+	_ = view.build()
+	return true
+}
+
+// More returns the number of rows remaining after a limit. See [LimitView].
+func (view *ProjectView) More() int {
+	return moreRows(view.resultSet)
+}
+
+// FilterView is a view that filters the rows of a result set with a
+// predicate.
+type FilterView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// pred is the predicate.
+	pred func([]any) bool
+	// columnTypes is the column types func.
+	columnTypes func(ResultSet, []any, int) error
+	// clen is the column count of the wrapped result set.
+	clen int
+	// row is the current row.
+	row []any
+	// err is the last encountered error.
+	err error
+}
+
+// NewFilterView creates a view of the rows of a result set for which pred
+// returns true. The predicate is passed the scanned values of each row, which
+// it should not retain. Use [WithUseColumnTypes] to scan rows using the
+// column types of the result set. Returns [ErrFilterPredicateIsNil] when pred
+// is nil.
+func NewFilterView(resultSet ResultSet, pred func(row []any) bool, opts ...Option) (*FilterView, error) {
+	if pred == nil {
+		return nil, ErrFilterPredicateIsNil
+	}
+	view := &FilterView{
+		resultSet: resultSet,
+		pred:      pred,
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// Next satisfies the ResultSet interface.
+func (view *FilterView) Next() bool {
+	if view.err != nil {
+		return false
+	}
+	if view.row = nil; view.clen == 0 {
+		cols, err := view.resultSet.Columns()
+		if err != nil {
+			view.err = err
+			return false
+		}
+		view.clen = len(cols)
+	}
+	for view.resultSet.Next() {
+		r, err := buildColumnTypes(view.resultSet, view.clen, view.columnTypes)
+		if err != nil {
+			view.err = err
+			return false
+		}
+		if err := view.resultSet.Scan(r...); err != nil {
+			view.err = err
+			return false
+		}
+		for i := range r {
+			r[i] = deref(r[i])
+		}
+		if view.pred(r) {
+			view.row = r
+			return true
+		}
+	}
+	return false
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *FilterView) Scan(v ...any) error {
+	if view.row == nil {
+		return sql.ErrNoRows
+	}
+	for i := 0; i < len(view.row) && i < len(v); i++ {
+		if err := assign(v[i], view.row[i], -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *FilterView) Columns() ([]string, error) {
+	return view.resultSet.Columns()
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *FilterView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return resultSetColumnTypes(view.resultSet)
+}
+
+// Close satisfies the ResultSet interface.
+func (view *FilterView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *FilterView) Err() error {
+	if view.err != nil {
+		return view.err
+	}
+	return view.resultSet.Err()
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *FilterView) NextResultSet() bool {
+	if view.err != nil || !view.resultSet.NextResultSet() {
+		return false
+	}
+	view.clen, view.row = 0, nil
+	return true
+}
+
+// More returns the number of rows remaining after a limit. See [LimitView].
+func (view *FilterView) More() int {
+	return moreRows(view.resultSet)
+}
+
+// LimitView is a view that skips the first rows of a result set and limits
+// the number of rows. When rows remain after the limit, a "... more rows"
+// notice is written by the table and expanded encoders. Use [WithCountMore] to
+// count the remaining rows, written as a "... N more rows" notice.
+type LimitView struct {
+	// resultSet is the wrapped result set.
+	resultSet ResultSet
+	// offset is the number of rows to skip.
+	offset int
+	// limit is the maximum number of rows.
+	limit int
+	// skipped is the number of skipped rows.
+	skipped int
+	// n is the number of rows.
+	n int
+	// count is whether to count the rows remaining after the limit.
+	count bool
+	// more is the number of rows remaining after the limit, or -1 when not
+	// counted.
+	more int
+}
+
+// NewLimitView creates a view skipping offset rows of a result set and
+// returning at most limit rows. A negative limit returns all rows. The offset
+// and limit apply to each result set.
+func NewLimitView(resultSet ResultSet, offset, limit int, opts ...Option) (*LimitView, error) {
+	view := &LimitView{
+		resultSet: resultSet,
+		offset:    offset,
+		limit:     limit,
+	}
+	for _, o := range opts {
+		if err := o.apply(view); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// Next satisfies the ResultSet interface.
+func (view *LimitView) Next() bool {
+	for ; view.skipped < view.offset; view.skipped++ {
+		if !view.resultSet.Next() {
+			return false
+		}
+	}
+	if view.limit < 0 || view.n < view.limit {
+		if view.resultSet.Next() {
+			view.n++
+			return true
+		}
+		return false
+	}
+	switch {
+	case view.more != 0:
+	case view.count:
+		for view.resultSet.Next() {
+			view.more++
+		}
+	case view.resultSet.Next():
+		view.more = -1
+	}
+	return false
+}
+
+// Scan satisfies the ResultSet interface.
+func (view *LimitView) Scan(v ...any) error {
+	return view.resultSet.Scan(v...)
+}
+
+// Columns satisfies the ResultSet interface.
+func (view *LimitView) Columns() ([]string, error) {
+	return view.resultSet.Columns()
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *LimitView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return resultSetColumnTypes(view.resultSet)
+}
+
+// Close satisfies the ResultSet interface.
+func (view *LimitView) Close() error {
+	return view.resultSet.Close()
+}
+
+// Err satisfies the ResultSet interface.
+func (view *LimitView) Err() error {
+	return view.resultSet.Err()
+}
+
+// NextResultSet satisfies the ResultSet interface.
+func (view *LimitView) NextResultSet() bool {
+	if !view.resultSet.NextResultSet() {
+		return false
+	}
+	view.skipped, view.n, view.more = 0, 0, 0
+	return true
+}
+
+// More returns the number of rows remaining after the limit, once the rows of
+// the result set have been read. Returns -1 when rows remain after the limit,
+// but were not counted.
+func (view *LimitView) More() int {
+	return view.more
+}
+
 // vkeyAppend determines if k is in v, if so it returns the unmodified v.
 // Otherwise, appends k to v.
 func vkeyAppend(v []string, k string) []string {
@@ -270,8 +1466,9 @@ func vkeyAppend(v []string, k string) []string {
 
 // hkey wraps a horizontal column.
 type hkey struct {
-	v string
-	s int
+	v   string
+	raw any
+	s   any
 }
 
 // hkeyAppend determines if k is in v, if so it returns the unmodified v.